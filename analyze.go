@@ -0,0 +1,72 @@
+package lemin
+
+import "lem-in/internal/utils"
+
+// Analysis describes where a colony limits the flow of ants.
+type Analysis struct {
+	// Cut is a smallest set of rooms separating the start room from the end
+	// room. Its size is the most paths the ants can use at once, so these are
+	// the bottleneck rooms to widen. It is empty when a tunnel joins the start
+	// and end rooms directly.
+	Cut []string `json:"cut"`
+	// DirectLink is set when a tunnel joins the start and end rooms, which no
+	// set of rooms can separate.
+	DirectLink bool `json:"directLink"`
+	// Rooms gives the use of every room other than the start room, in
+	// declaration order. It is empty when no solution is given.
+	Rooms []RoomUsage `json:"rooms"`
+}
+
+// RoomUsage is how much the ants of a solution use a room.
+type RoomUsage struct {
+	Name       string `json:"name"`
+	Ants       int    `json:"ants"`       // Ants passing through the room
+	Turns      []int  `json:"turns"`      // 1-based turns at which an ant enters the room, in order
+	Bottleneck bool   `json:"bottleneck"` // Whether the room is part of the cut
+}
+
+// Analyze finds the bottleneck rooms of the colony and, when s is not nil,
+// how many ants pass through each room and when.
+func Analyze(c *Colony, s *Solution) *Analysis {
+	cut, separable := utils.MinVertexCut(c.antColony())
+	analysis := &Analysis{Cut: cut, DirectLink: !separable && hasLink(c, c.Start, c.End)}
+	if analysis.Cut == nil {
+		analysis.Cut = []string{}
+	}
+	if s == nil {
+		return analysis
+	}
+
+	inCut := make(map[string]bool, len(cut))
+	for _, room := range cut {
+		inCut[room] = true
+	}
+	usage := make(map[string]*RoomUsage, len(c.Rooms))
+	for _, room := range c.Rooms {
+		if room.Name != c.Start {
+			analysis.Rooms = append(analysis.Rooms, RoomUsage{Name: room.Name, Turns: []int{}, Bottleneck: inCut[room.Name]})
+		}
+	}
+	for i := range analysis.Rooms {
+		usage[analysis.Rooms[i].Name] = &analysis.Rooms[i]
+	}
+	for turn, moves := range Simulate(s) {
+		for _, move := range moves {
+			if room, ok := usage[move.Room]; ok {
+				room.Ants++
+				room.Turns = append(room.Turns, turn+1)
+			}
+		}
+	}
+	return analysis
+}
+
+// hasLink reports whether a tunnel joins the two rooms.
+func hasLink(c *Colony, a, b string) bool {
+	for _, link := range c.Links {
+		if link.From == a && link.To == b || link.From == b && link.To == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package lemin
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// AnimationOptions configures WriteGIF.
+type AnimationOptions struct {
+	// RenderOptions draw the colony under the ants. Its Solution is the one
+	// animated and must be set.
+	RenderOptions
+	// Delay is how long each frame is shown, 250 milliseconds when zero. GIF
+	// counts delays in hundredths of a second.
+	Delay time.Duration
+	// FramesPerTurn is the number of frames drawn for each turn, 1 when zero.
+	// With more, the ants are also drawn part way along the tunnels.
+	FramesPerTurn int
+}
+
+// antRadius is the radius of the dot drawn for an ant.
+const antRadius = 5
+
+// WriteGIF writes an animated GIF of the ants moving turn by turn, following
+// the moves of Simulate. The first frame has every ant in the start room and
+// each ant is drawn in the colour of its path until it reaches the end room. A
+// turn counter is drawn in the top left corner and the last frame is held for
+// at least a second before the animation loops.
+func WriteGIF(w io.Writer, c *Colony, opts AnimationOptions) error {
+	s := opts.Solution
+	if s == nil {
+		return errors.New("no solution to animate")
+	}
+	delay := opts.Delay
+	if delay <= 0 {
+		delay = 250 * time.Millisecond
+	}
+	framesPerTurn := max(opts.FramesPerTurn, 1)
+
+	background, layout := render(c, opts.RenderOptions)
+	base := image.NewPaletted(background.Bounds(), imagePalette(background))
+	draw.Draw(base, base.Bounds(), background, image.Point{}, draw.Src)
+
+	// Ants are numbered from 1 and drawn in the colour of their path
+	ants := 0
+	for _, path := range s.Ants {
+		for _, ant := range path {
+			ants = max(ants, ant)
+		}
+	}
+	colors := make([]color.Color, ants+1)
+	for i, path := range s.Ants {
+		pathColor, _ := parseColor(pathColors[i%len(pathColors)])
+		for _, ant := range path {
+			colors[ant] = pathColor
+		}
+	}
+	before, after := make([]string, ants+1), make([]string, ants+1) // Rooms of each ant around the turn
+	for ant := range before {
+		before[ant], after[ant] = c.Start, c.Start
+	}
+
+	anim := &gif.GIF{Config: image.Config{ColorModel: base.Palette, Width: base.Rect.Dx(), Height: base.Rect.Dy()}}
+	counter := fmt.Sprintf("turn %d/%d", s.Turns, s.Turns)
+	counterRect := image.Rect(6, 6, 14+textWidth(counter, 2), 14+2*glyphHeight)
+	drawn := base.Bounds() // Part of the image the previous frame changed
+	addFrame := func(turn int, progress float64) {
+		// Only the dots of the previous frame and of this one are redrawn
+		var dots []image.Point
+		var dotColors []color.Color
+		for ant := 1; ant <= ants; ant++ {
+			if after[ant] == c.Start || before[ant] == c.End {
+				continue
+			}
+			x1, y1 := layout.point(before[ant])
+			x2, y2 := layout.point(after[ant])
+			dots = append(dots, image.Pt(x1+int(float64(x2-x1)*progress), y1+int(float64(y2-y1)*progress)))
+			dotColors = append(dotColors, colors[ant])
+		}
+		rect := drawn
+		drawn = counterRect
+		for _, dot := range dots {
+			drawn = drawn.Union(image.Rect(dot.X-antRadius, dot.Y-antRadius, dot.X+antRadius+1, dot.Y+antRadius+1))
+		}
+		rect = rect.Union(drawn).Intersect(base.Bounds())
+
+		frame := image.NewPaletted(rect, base.Palette)
+		for y := rect.Min.Y; y < rect.Max.Y; y++ {
+			copy(frame.Pix[frame.PixOffset(rect.Min.X, y):frame.PixOffset(rect.Max.X, y)], base.Pix[base.PixOffset(rect.Min.X, y):])
+		}
+		for i, dot := range dots {
+			fillCircle(frame, dot.X, dot.Y, antRadius, color.Black)
+			fillCircle(frame, dot.X, dot.Y, antRadius-1, dotColors[i])
+		}
+		draw.Draw(frame, counterRect, image.White, image.Point{}, draw.Src)
+		drawText(frame, 10, 10, fmt.Sprintf("turn %d/%d", turn, s.Turns), color.Black, 2)
+
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, max(int(delay/(10*time.Millisecond)), 1))
+	}
+
+	addFrame(0, 0)
+	for turn, moves := range Simulate(s) {
+		copy(before, after)
+		for _, move := range moves {
+			after[move.Ant] = move.Room
+		}
+		for i := 1; i <= framesPerTurn; i++ {
+			addFrame(turn+1, float64(i)/float64(framesPerTurn))
+		}
+	}
+	last := len(anim.Delay) - 1
+	anim.Delay[last] = max(anim.Delay[last], 100)
+	return gif.EncodeAll(w, anim)
+}
+
+// imagePalette returns the colours of the image, black and white, and those of
+// the paths, or the Plan 9 palette when there are too many for a GIF.
+func imagePalette(img *image.RGBA) color.Palette {
+	seen := make(map[color.RGBA]bool)
+	var colors color.Palette
+	add := func(c color.RGBA) {
+		if !seen[c] {
+			seen[c] = true
+			colors = append(colors, c)
+		}
+	}
+	add(color.RGBA{0xff, 0xff, 0xff, 0xff})
+	add(color.RGBA{0x00, 0x00, 0x00, 0xff})
+	for _, hex := range pathColors {
+		c, _ := parseColor(hex)
+		add(c)
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		add(color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]})
+		if len(colors) > 256 {
+			return palette.Plan9
+		}
+	}
+	return colors
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"lem-in"
+)
+
+const analyzeUsage = "Usage: go run main.go analyze [--format text|json] [--dot path] [--svg path] [--timeout d] [--strict | --lenient] file.txt|-"
+
+// runAnalyze reports the bottleneck rooms of a map and how much the solution
+// uses each room, and exports the map with the bottlenecks highlighted.
+func runAnalyze(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lem-in analyze", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "text", "report format: text or json")
+	dotPath := flags.String("dot", "", "write the map as a Graphviz graph with the bottlenecks highlighted")
+	svgPath := flags.String("svg", "", "write the map as an SVG image with the bottlenecks highlighted")
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, 0 for no limit")
+	strict := flags.Bool("strict", false, "parse the map in strict mode")
+	lenient := flags.Bool("lenient", false, "parse the map in lenient mode")
+	report := reporter{w: stderr}
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil || flags.NArg() != 1 || (*strict && *lenient) || (*format != "text" && *format != "json") {
+		return report.usage(analyzeUsage)
+	}
+
+	colony, err := readColony(flags.Arg(0), stdin, lemin.ParseOptions{Mode: parseMode(*strict, *lenient)})
+	if err != nil {
+		return report.fail(err)
+	}
+	solution, err := solve(colony, lemin.SolveOptions{}, *timeout)
+	if err != nil {
+		return report.fail(err)
+	}
+	analysis := lemin.Analyze(colony, solution)
+
+	exportOpts := lemin.ExportOptions{Solution: solution, Highlight: analysis.Cut}
+	for _, export := range []struct {
+		path  string
+		write func(io.Writer, *lemin.Colony, lemin.ExportOptions) error
+	}{{*dotPath, lemin.ExportDOT}, {*svgPath, lemin.ExportSVG}} {
+		if export.path == "" {
+			continue
+		}
+		if err := writeExport(export.path, colony, exportOpts, export.write); err != nil {
+			return report.fail(err)
+		}
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(analysis)
+	} else {
+		err = writeAnalysis(stdout, colony, analysis)
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+	return exitOK
+}
+
+// writeExport writes an export of the colony to the named file.
+func writeExport(path string, colony *lemin.Colony, opts lemin.ExportOptions, write func(io.Writer, *lemin.Colony, lemin.ExportOptions) error) error {
+	out, closeOutput, err := openOutput(path, nil)
+	if err != nil {
+		return err
+	}
+	if err := write(out, colony, opts); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
+
+// writeAnalysis writes the bottlenecks followed by a table of room usage.
+func writeAnalysis(w io.Writer, colony *lemin.Colony, analysis *lemin.Analysis) error {
+	switch {
+	case analysis.DirectLink:
+		fmt.Fprintf(w, "no bottleneck: a tunnel joins %s and %s directly\n", colony.Start, colony.End)
+	default:
+		fmt.Fprintf(w, "bottlenecks: %s\n", strings.Join(analysis.Cut, ", "))
+		fmt.Fprintf(w, "at most %d paths can be used at once\n", len(analysis.Cut))
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "room\tants\tturns\tbottleneck")
+	for _, room := range analysis.Rooms {
+		bottleneck := ""
+		if room.Bottleneck {
+			bottleneck = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", room.Name, room.Ants, turnRanges(room.Turns), bottleneck)
+	}
+	return tw.Flush()
+}
+
+// turnRanges writes sorted turns compactly, with runs of consecutive turns as
+// first-last: 1-4, 6. Repeated turns, as in the end room, are written once.
+func turnRanges(turns []int) string {
+	if len(turns) == 0 {
+		return "-"
+	}
+	var parts []string
+	for i := 0; i < len(turns); {
+		j := i
+		for j+1 < len(turns) && turns[j+1]-turns[j] <= 1 {
+			j++
+		}
+		part := strconv.Itoa(turns[i])
+		if turns[j] > turns[i] {
+			part += "-" + strconv.Itoa(turns[j])
+		}
+		parts = append(parts, part)
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
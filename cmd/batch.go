@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"lem-in"
+)
+
+const batchUsage = "Usage: go run main.go batch [-j N] [--format table|csv|json] [--timeout d] [--strict | --lenient] file|dir|glob..."
+
+// batchResult is one row of the batch summary.
+type batchResult struct {
+	File     string        `json:"file"`
+	Status   string        `json:"status"` // The exit category: ok, io, parse, unsolvable, timeout or internal
+	Ants     int           `json:"ants"`
+	Rooms    int           `json:"rooms"`
+	Links    int           `json:"links"`
+	Paths    int           `json:"paths"`
+	Turns    int           `json:"turns"`
+	Duration time.Duration `json:"-"`
+	Millis   float64       `json:"timeMs"`
+	Error    string        `json:"error,omitempty"`
+
+	code int // The exit code for the map
+}
+
+// runBatch solves every map named by the arguments with a pool of workers and
+// writes a summary with one row per map, in the order the maps were named. It
+// returns exitOK when every map was solved and the exit code of the first
+// failed map otherwise.
+func runBatch(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lem-in batch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	jobs := flags.Int("j", runtime.NumCPU(), "number of maps solved at once")
+	format := flags.String("format", "table", "summary format: table, csv or json")
+	timeout := flags.Duration("timeout", 0, "give up solving a map after this long, 0 for no limit")
+	strict := flags.Bool("strict", false, "parse the maps in strict mode")
+	lenient := flags.Bool("lenient", false, "parse the maps in lenient mode")
+	report := reporter{w: stderr}
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || *jobs < 1 || (*strict && *lenient) {
+		return report.usage(batchUsage)
+	}
+	if *format != "table" && *format != "csv" && *format != "json" {
+		return report.usage(batchUsage)
+	}
+
+	files, err := expandMaps(flags.Args())
+	if err != nil {
+		return report.usage("ERROR: " + err.Error())
+	}
+	opts := lemin.ParseOptions{Mode: parseMode(*strict, *lenient)}
+	results := solveAll(files, *jobs, opts, *timeout)
+
+	switch *format {
+	case "csv":
+		err = writeBatchCSV(stdout, results)
+	case "json":
+		err = writeBatchJSON(stdout, results)
+	default:
+		err = writeBatchTable(stdout, results)
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+	for _, result := range results {
+		if result.code != exitOK {
+			return result.code
+		}
+	}
+	return exitOK
+}
+
+// expandMaps turns the arguments into a list of files. Globs are expanded and
+// directories stand for the .txt and .txt.gz files they hold.
+func expandMaps(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %v", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no maps match %s", arg)
+			}
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || !info.IsDir() {
+				files = append(files, match) // Unreadable files are reported in their row
+				continue
+			}
+			for _, pattern := range []string{"*.txt", "*.txt.gz"} {
+				inDir, err := filepath.Glob(filepath.Join(match, pattern))
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, inDir...)
+			}
+		}
+	}
+	return files, nil
+}
+
+// solveAll solves the files with the given number of workers.
+func solveAll(files []string, jobs int, opts lemin.ParseOptions, timeout time.Duration) []batchResult {
+	results := make([]batchResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = solveMap(files[i], opts, timeout)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// solveMap parses and solves one map. A failure, even a panic, only affects its own row.
+func solveMap(filename string, opts lemin.ParseOptions, timeout time.Duration) (result batchResult) {
+	result.File = filename
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.fail(fmt.Errorf("%w: %v", errInternal, r))
+		}
+		result.Status = categories[result.code]
+		result.Duration = time.Since(start)
+		result.Millis = float64(result.Duration.Microseconds()) / 1000
+	}()
+
+	colony, err := lemin.ParseFile(filename, opts)
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+	result.Ants = colony.Ants
+	result.Rooms = len(colony.Rooms)
+	result.Links = len(colony.Links)
+
+	solution, err := solve(colony, lemin.SolveOptions{}, timeout)
+	if err != nil {
+		result.fail(err)
+		return result
+	}
+	result.Paths = len(solution.Paths)
+	result.Turns = solution.Turns
+	return result
+}
+
+// fail records the error that stopped the map.
+func (r *batchResult) fail(err error) {
+	r.Error = err.Error()
+	r.code = exitCode(err)
+}
+
+var batchColumns = []string{"file", "status", "ants", "rooms", "links", "paths", "turns", "time", "error"}
+
+// fields returns the row's values as text, in the order of batchColumns.
+func (r batchResult) fields() []string {
+	return []string{
+		r.File,
+		r.Status,
+		strconv.Itoa(r.Ants),
+		strconv.Itoa(r.Rooms),
+		strconv.Itoa(r.Links),
+		strconv.Itoa(r.Paths),
+		strconv.Itoa(r.Turns),
+		r.Duration.Round(time.Microsecond).String(),
+		r.Error,
+	}
+}
+
+func writeBatchTable(w io.Writer, results []batchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(batchColumns, "\t")))
+	failed := 0
+	for _, result := range results {
+		fmt.Fprintln(tw, strings.Join(result.fields(), "\t"))
+		if result.Error != "" {
+			failed++
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d maps, %d solved, %d failed\n", len(results), len(results)-failed, failed)
+	return err
+}
+
+func writeBatchCSV(w io.Writer, results []batchResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write(batchColumns)
+	for _, result := range results {
+		cw.Write(result.fields())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeBatchJSON(w io.Writer, results []batchResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
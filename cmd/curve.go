@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+
+	"lem-in"
+)
+
+const curveUsage = "Usage: go run main.go curve [--max N] [--format csv|svg] [--output path] [--strict | --lenient] file.txt|-"
+
+// runCurve writes the turns needed for every number of ants from 1 to --max,
+// the colony's own ant count by default, as CSV or as an SVG chart.
+func runCurve(args []string, stdin io.Reader, stdout, stderr io.Writer) (code int) {
+	flags := flag.NewFlagSet("lem-in curve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	maxAnts := flags.Int("max", 0, "largest number of ants, 0 for the colony's")
+	format := flags.String("format", "csv", "output format: csv or svg")
+	output := flags.String("output", "", "write the curve to this file instead of stdout")
+	strict := flags.Bool("strict", false, "parse the map in strict mode")
+	lenient := flags.Bool("lenient", false, "parse the map in lenient mode")
+	report := reporter{w: stderr}
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil || flags.NArg() != 1 || *maxAnts < 0 || (*strict && *lenient) || (*format != "csv" && *format != "svg") {
+		return report.usage(curveUsage)
+	}
+
+	colony, err := readColony(flags.Arg(0), stdin, lemin.ParseOptions{Mode: parseMode(*strict, *lenient)})
+	if err != nil {
+		return report.fail(err)
+	}
+	if *maxAnts == 0 {
+		*maxAnts = colony.Ants
+	}
+	points, err := lemin.Curve(colony, *maxAnts)
+	if err != nil {
+		return report.fail(err)
+	}
+
+	out, closeOutput, err := openOutput(*output, stdout)
+	if err != nil {
+		return report.fail(err)
+	}
+	defer func() {
+		if err := closeOutput(); err != nil && code == exitOK {
+			code = report.fail(err)
+		}
+	}()
+	if *format == "svg" {
+		err = lemin.WriteCurveSVG(out, points)
+	} else {
+		err = lemin.WriteCurveCSV(out, points)
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+	return exitOK
+}
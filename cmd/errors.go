@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"time"
+
+	"lem-in"
+)
+
+// Exit codes, one per category of failure.
+const (
+	exitOK         = 0
+	exitInternal   = 1 // A bug: a panic or an unexpected solver error
+	exitUsage      = 2 // Invalid flags or arguments
+	exitIO         = 3 // A file could not be read or written
+	exitParse      = 4 // The map is invalid
+	exitUnsolvable = 5 // No path leads from the start room to the end room
+	exitTimeout    = 6 // Solving took longer than --timeout
+)
+
+var categories = map[int]string{
+	exitOK:         "ok",
+	exitInternal:   "internal",
+	exitUsage:      "usage",
+	exitIO:         "io",
+	exitParse:      "parse",
+	exitUnsolvable: "unsolvable",
+	exitTimeout:    "timeout",
+}
+
+var (
+	errInternal = errors.New("internal error")
+	errTimeout  = errors.New("timed out while solving")
+)
+
+// exitCode returns the exit code for err.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errTimeout):
+		return exitTimeout
+	case errors.Is(err, lemin.ErrNoPath):
+		return exitUnsolvable
+	case errors.Is(err, errInternal):
+		return exitInternal
+	case len(lemin.ParseErrors(err)) > 0:
+		return exitParse
+	case errors.As(err, new(*fs.PathError)), errors.Is(err, fs.ErrNotExist):
+		return exitIO
+	}
+	return exitInternal
+}
+
+// solve runs the solver, stopping it after timeout when it is positive. A
+// panic in the solver is returned as an internal error.
+func solve(colony *lemin.Colony, opts lemin.SolveOptions, timeout time.Duration) (solution *lemin.Solution, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			solution, err = nil, fmt.Errorf("%w: %v", errInternal, r)
+		}
+	}()
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	opts.Context = ctx
+	solution, err = lemin.Solve(colony, opts)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return nil, fmt.Errorf("%w after %v", errTimeout, timeout)
+	case err != nil && !errors.Is(err, lemin.ErrNoPath):
+		return nil, fmt.Errorf("%w: %v", errInternal, err)
+	}
+	return solution, err
+}
+
+// reporter writes errors to stderr as text or, with --errors json, as one JSON object per failure.
+type reporter struct {
+	w    io.Writer
+	json bool
+}
+
+// jsonError is the structured form of a failure.
+type jsonError struct {
+	Category string           `json:"category"`
+	Code     int              `json:"code"`
+	Message  string           `json:"message"`
+	Errors   []jsonParseError `json:"errors,omitempty"`
+}
+
+type jsonParseError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// fail reports err and returns its exit code.
+func (r reporter) fail(err error) int {
+	code := exitCode(err)
+	parseErrs := lemin.ParseErrors(err)
+	if r.json {
+		out := jsonError{Category: categories[code], Code: code, Message: err.Error()}
+		for _, parseErr := range parseErrs {
+			out.Errors = append(out.Errors, jsonParseError{File: parseErr.File, Line: parseErr.Line, Message: parseErr.Err.Error()})
+		}
+		json.NewEncoder(r.w).Encode(out)
+		return code
+	}
+
+	switch code {
+	case exitParse:
+		for _, parseErr := range parseErrs {
+			fmt.Fprintln(r.w, "ERROR: invalid data format,", parseErr)
+		}
+	case exitUnsolvable:
+		fmt.Fprintln(r.w, "ERROR: invalid data format,", err)
+	default:
+		fmt.Fprintln(r.w, "ERROR:", err)
+	}
+	return code
+}
+
+// usage reports a usage error and returns its exit code.
+func (r reporter) usage(lines ...string) int {
+	if r.json {
+		json.NewEncoder(r.w).Encode(jsonError{Category: categories[exitUsage], Code: exitUsage, Message: lines[0]})
+		return exitUsage
+	}
+	for _, line := range lines {
+		fmt.Fprintln(r.w, line)
+	}
+	return exitUsage
+}
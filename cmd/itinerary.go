@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"lem-in"
+)
+
+// writeItineraries writes one line per ant with its path, numbered from 1,
+// the turns it leaves and arrives, and the rooms it enters as turn:room,
+// followed by the mean and longest time an ant spends in the colony.
+func writeItineraries(w io.Writer, s *lemin.Solution) error {
+	itineraries := lemin.Itineraries(s)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ant\tpath\tdepart\tfinish\tturns\troute")
+	total, longest := 0, 0
+	for i, it := range itineraries {
+		stops := make([]string, len(it.Stops))
+		for j, stop := range it.Stops {
+			stops[j] = strconv.Itoa(stop.Turn) + ":" + stop.Room
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%d\t%s\n", it.Ant, it.Path+1, it.Depart, it.Finish, it.Turns(), strings.Join(stops, " "))
+		total += it.Turns()
+		if it.Turns() > itineraries[longest].Turns() {
+			longest = i
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(itineraries) == 0 {
+		_, err := fmt.Fprintln(w, "\nno ants")
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d ants, %.2f turns in the colony on average, %d at most (ant %d)\n",
+		len(itineraries), float64(total)/float64(len(itineraries)), itineraries[longest].Turns(), itineraries[longest].Ant)
+	return err
+}
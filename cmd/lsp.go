@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"lem-in"
+)
+
+// runLSP serves the Language Server Protocol over stdin and stdout until the
+// client sends exit. Maps are validated with the parser after every change and
+// the results published as diagnostics.
+func runLSP(stdin io.Reader, stdout, stderr io.Writer) int {
+	server := &lspServer{
+		in:       bufio.NewReader(stdin),
+		out:      stdout,
+		docs:     make(map[string]string),
+		versions: make(map[string]int),
+	}
+	err := server.serve()
+	server.checks.Wait()
+	if err != nil {
+		fmt.Fprintln(stderr, "ERROR:", err)
+		return exitIO
+	}
+	if !server.shutdown {
+		return exitInternal // The protocol asks for 1 when exit comes without shutdown
+	}
+	return exitOK
+}
+
+// Diagnostics are computed off the read loop once a document has been left
+// unchanged for diagnosticsDelay, so a burst of edits is checked once, and the
+// solver run behind the unreachable end warning is given up after checkTimeout.
+const (
+	diagnosticsDelay = 100 * time.Millisecond
+	checkTimeout     = 2 * time.Second
+)
+
+type lspServer struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]string // Open documents by URI
+	shutdown bool
+
+	writing  sync.Mutex     // Serializes writes to out
+	checking sync.Mutex     // Guards versions
+	versions map[string]int // Changes seen for each document, to drop outdated diagnostics
+	checks   sync.WaitGroup // Diagnostics being computed
+}
+
+// lspMessage is a JSON-RPC request, notification or response.
+type lspMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *lspError       `json:"error,omitempty"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC and LSP error codes.
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeRequestFailed  = -32803
+)
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"` // UTF-16 code units
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"` // 1 error, 2 warning
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type textDocumentParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Position lspPosition `json:"position"`
+	NewName  string      `json:"newName"`
+}
+
+// serve handles messages until exit or the end of the input.
+func (s *lspServer) serve() error {
+	for {
+		msg, err := s.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil {
+			continue // Notifications get no response
+		}
+		response := lspMessage{JSONRPC: "2.0", ID: msg.ID, Result: result, Error: rpcErr}
+		if rpcErr == nil && result == nil {
+			response.Result = json.RawMessage("null")
+		}
+		if err := s.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+// read reads one message framed by a Content-Length header.
+func (s *lspServer) read() (*lspMessage, error) {
+	length := -1
+	for {
+		header, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		header = strings.TrimRight(header, "\r\n")
+		if header == "" {
+			break
+		}
+		name, value, _ := strings.Cut(header, ":")
+		if strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message without Content-Length")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+	return &msg, nil
+}
+
+func (s *lspServer) write(msg lspMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	s.writing.Lock()
+	defer s.writing.Unlock()
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+func (s *lspServer) notify(method string, params any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(lspMessage{Method: method, Params: body})
+}
+
+// handle runs a request or notification and returns the result of a request.
+func (s *lspServer) handle(msg *lspMessage) (any, *lspError) {
+	var params textDocumentParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{Code: codeInvalidParams, Message: err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // Full document on every change
+				"definitionProvider": true,
+				"hoverProvider":      true,
+				"renameProvider":     true,
+				"completionProvider": map[string]any{"triggerCharacters": []string{"-"}},
+			},
+			"serverInfo": map[string]string{"name": "lem-in", "version": lemin.Version},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		s.docs[uri] = params.TextDocument.Text
+		s.scheduleDiagnostics(uri)
+		return nil, nil
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[uri] = params.ContentChanges[n-1].Text
+		}
+		s.scheduleDiagnostics(uri)
+		return nil, nil
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		s.checking.Lock()
+		s.versions[uri]++ // Drops the pending check
+		s.checking.Unlock()
+		s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": []lspDiagnostic{}})
+		return nil, nil
+
+	case "textDocument/definition":
+		return s.definition(uri, params.Position), nil
+	case "textDocument/hover":
+		return s.hover(uri, params.Position), nil
+	case "textDocument/rename":
+		return s.rename(uri, params.Position, params.NewName)
+	case "textDocument/completion":
+		return s.completion(uri, params.Position), nil
+	}
+
+	if msg.ID == nil || strings.HasPrefix(msg.Method, "$/") {
+		return nil, nil
+	}
+	return nil, &lspError{Code: codeMethodNotFound, Message: "unsupported method: " + msg.Method}
+}
+
+// scheduleDiagnostics checks the document after diagnosticsDelay and
+// publishes its problems, unless it changed again in the meantime.
+func (s *lspServer) scheduleDiagnostics(uri string) {
+	s.checking.Lock()
+	s.versions[uri]++
+	version := s.versions[uri]
+	s.checking.Unlock()
+	text := s.docs[uri]
+
+	s.checks.Add(1)
+	time.AfterFunc(diagnosticsDelay, func() {
+		defer s.checks.Done()
+		if !s.current(uri, version) {
+			return
+		}
+		diagnostics := checkDocument(uri, text)
+
+		// Publishing under the lock keeps an outdated result from following a newer one
+		s.checking.Lock()
+		defer s.checking.Unlock()
+		if s.versions[uri] == version {
+			s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": diagnostics})
+		}
+	})
+}
+
+// current reports whether version is the latest change of the document.
+func (s *lspServer) current(uri string, version int) bool {
+	s.checking.Lock()
+	defer s.checking.Unlock()
+	return s.versions[uri] == version
+}
+
+// checkDocument parses a document and returns its problems, and a warning
+// when the end room cannot be reached. Problems in included files are shown
+// on the ##include line they come from, and a panic is reported as an error
+// instead of stopping the server.
+func checkDocument(uri, text string) (diagnostics []lspDiagnostic) {
+	lines := strings.Split(text, "\n")
+	diagnostics = []lspDiagnostic{}
+	add := func(line int, severity int, message string) {
+		if line < 0 || line >= len(lines) {
+			line = 0
+		}
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range:    lineRange(lines, line),
+			Severity: severity,
+			Source:   "lem-in",
+			Message:  message,
+		})
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			add(0, 1, fmt.Sprintf("internal error: %v", r))
+		}
+	}()
+
+	colony, err := lemin.Parse(strings.NewReader(text), lemin.ParseOptions{AllErrors: true, Filename: uriPath(uri)})
+	if err != nil {
+		parseErrs := lemin.ParseErrors(err)
+		if len(parseErrs) == 0 {
+			add(0, 1, err.Error())
+		}
+		for _, parseErr := range parseErrs {
+			switch {
+			case parseErr.File != "":
+				add(parseErr.IncludeLine-1, 1, parseErr.Error())
+			case parseErr.Line > 0:
+				add(parseErr.Line-1, 1, parseErr.Err.Error())
+			default:
+				add(0, 1, parseErr.Err.Error())
+			}
+		}
+		return diagnostics
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+	if _, err := lemin.Solve(colony, lemin.SolveOptions{Context: ctx}); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		add(0, 2, err.Error())
+	}
+	return diagnostics
+}
+
+// nameAt returns the outline of the document and the room name at a position.
+func (s *lspServer) nameAt(uri string, pos lspPosition) (*lemin.Outline, []string, string, bool) {
+	text, open := s.docs[uri]
+	if !open {
+		return nil, nil, "", false
+	}
+	lines := strings.Split(text, "\n")
+	outline := lemin.NewOutline(text)
+	if pos.Line >= len(lines) {
+		return outline, lines, "", false
+	}
+	name, found := outline.NameAt(pos.Line, byteOffset(lines[pos.Line], pos.Character))
+	return outline, lines, name, found
+}
+
+// definition goes from a room name in a link or annotation to its room line.
+func (s *lspServer) definition(uri string, pos lspPosition) any {
+	outline, lines, name, found := s.nameAt(uri, pos)
+	if !found {
+		return nil
+	}
+	room, exists := outline.Room(name)
+	if !exists {
+		return nil
+	}
+	return lspLocation{URI: uri, Range: spanRange(lines, room.Span)}
+}
+
+// hover shows the coordinates and the number of links of a room.
+func (s *lspServer) hover(uri string, pos lspPosition) any {
+	outline, _, name, found := s.nameAt(uri, pos)
+	if !found {
+		return nil
+	}
+	room, exists := outline.Room(name)
+	if !exists {
+		return map[string]any{"contents": map[string]string{"kind": "markdown", "value": fmt.Sprintf("`%s`: no such room", name)}}
+	}
+	value := fmt.Sprintf("**%s** at (%d, %d), %d links", room.Name, room.X, room.Y, outline.Degree(room.Name))
+	return map[string]any{"contents": map[string]string{"kind": "markdown", "value": value}}
+}
+
+// rename renames the room under the cursor on its room line, in links and in annotations.
+func (s *lspServer) rename(uri string, pos lspPosition, newName string) (any, *lspError) {
+	outline, lines, name, found := s.nameAt(uri, pos)
+	if !found {
+		return nil, &lspError{Code: codeRequestFailed, Message: "no room name at this position"}
+	}
+	edits, err := outline.Rename(name, newName)
+	if err != nil {
+		return nil, &lspError{Code: codeRequestFailed, Message: err.Error()}
+	}
+
+	textEdits := make([]lspTextEdit, len(edits))
+	for i, edit := range edits {
+		textEdits[i] = lspTextEdit{Range: spanRange(lines, edit.Span), NewText: edit.Text}
+	}
+	return map[string]any{"changes": map[string][]lspTextEdit{uri: textEdits}}, nil
+}
+
+// completion offers the room names on link lines.
+func (s *lspServer) completion(uri string, pos lspPosition) any {
+	text, open := s.docs[uri]
+	if !open {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if pos.Line < len(lines) {
+		typed := lines[pos.Line][:byteOffset(lines[pos.Line], pos.Character)]
+		if strings.HasPrefix(strings.TrimSpace(typed), "#") || strings.Contains(strings.TrimSpace(typed), " ") {
+			return []any{} // Not a link line
+		}
+	}
+
+	outline := lemin.NewOutline(text)
+	rooms := outline.Rooms()
+	items := make([]map[string]any, 0, len(rooms))
+	for _, room := range rooms {
+		items = append(items, map[string]any{
+			"label":  room.Name,
+			"kind":   6, // Variable
+			"detail": fmt.Sprintf("(%d, %d), %d links", room.X, room.Y, outline.Degree(room.Name)),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i]["label"].(string) < items[j]["label"].(string) })
+	return items
+}
+
+// uriPath returns the file path of a file: URI, used to resolve includes.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// byteOffset converts a UTF-16 column, as used by LSP, to a byte offset in line.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// utf16Column converts a byte offset in line to a UTF-16 column.
+func utf16Column(line string, offset int) int {
+	units := 0
+	for i := 0; i < offset && i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		units += utf16.RuneLen(r)
+		i += size
+	}
+	return units
+}
+
+func spanRange(lines []string, span lemin.Span) lspRange {
+	line := lines[span.Line]
+	return lspRange{
+		Start: lspPosition{Line: span.Line, Character: utf16Column(line, span.Start)},
+		End:   lspPosition{Line: span.Line, Character: utf16Column(line, span.End)},
+	}
+}
+
+// lineRange covers a whole line.
+func lineRange(lines []string, line int) lspRange {
+	text := strings.TrimSuffix(lines[line], "\r")
+	return lspRange{
+		Start: lspPosition{Line: line},
+		End:   lspPosition{Line: line, Character: utf16Column(text, len(text))},
+	}
+}
@@ -1,35 +1,193 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"time"
 
-	"lem-in/resources"
-	"lem-in/utils"
+	"lem-in"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: go run main.go file.txt")
-		return
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+const (
+	usageLine        = "Usage: go run main.go [--strict | --lenient] [--all-errors] [--output path] [--moves-only | --no-echo | --by-ant] [--timeout d] [--reduce] [--errors text|json] [--trace path | --explain] [--stats] [--cpuprofile path] [--memprofile path] file.txt|-"
+	batchUsageLine   = "       go run main.go batch [-j N] [--format table|csv|json] [--timeout d] file|dir|glob..."
+	analyzeUsageLine = "       go run main.go analyze [--format text|json] [--dot path] [--svg path] file.txt|-"
+	curveUsageLine   = "       go run main.go curve [--max N] [--format csv|svg] [--output path] file.txt|-"
+	suggestUsageLine = "       go run main.go suggest (--distance d | --candidates file) [-k N] [--top N] [--format text|json] file.txt|-"
+	renderUsageLine  = "       go run main.go render (--png path | --gif path) [--delay d] [--frames-per-turn N] [--size N] [--no-labels] [--hide-unused] file.txt|-"
+	lspUsageLine     = "       go run main.go lsp"
+)
+
+// run executes the program for the given arguments, reading the map from stdin
+// when the file is "-", writing the output to stdout and errors to stderr. It
+// returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) (code int) {
+	if len(args) > 0 && args[0] == "batch" {
+		return runBatch(args[1:], stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "analyze" {
+		return runAnalyze(args[1:], stdin, stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "curve" {
+		return runCurve(args[1:], stdin, stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "suggest" {
+		return runSuggest(args[1:], stdin, stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "render" {
+		return runRender(args[1:], stdin, stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "lsp" {
+		return runLSP(stdin, stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("lem-in", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	strict := flags.Bool("strict", false, "reject unknown ## commands, tabs, trailing whitespace and rooms after links")
+	lenient := flags.Bool("lenient", false, "accept a byte order mark, tabs, extra whitespace and any case in directives")
+	allErrors := flags.Bool("all-errors", false, "report every problem in the map instead of stopping at the first")
+	output := flags.String("output", "", "write the map and moves to this file instead of stdout")
+	movesOnly := flags.Bool("moves-only", false, "write only the moves, without the map and the blank line after it")
+	noEcho := flags.Bool("no-echo", false, "leave out the map but keep the blank line before the moves")
+	byAnt := flags.Bool("by-ant", false, "write each ant's path and the turn it enters every room instead of the moves")
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, 0 for no limit")
+	reduce := flags.Bool("reduce", false, "prune dead ends and contract chains of rooms before solving")
+	errorFormat := flags.String("errors", "text", "error format: text or json")
+	tracePath := flags.String("trace", "", "write the solver's decisions to this file as JSON lines, - for stderr")
+	explain := flags.Bool("explain", false, "log the solver's decisions to stderr as text")
+	cpuProfile := flags.String("cpuprofile", "", "write a CPU profile to this file")
+	memProfile := flags.String("memprofile", "", "write a memory profile to this file")
+	showStats := flags.Bool("stats", false, "print solver counters and per-phase timings to stderr")
+	err := flags.Parse(args)
+	report := reporter{w: stderr, json: *errorFormat == "json"}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
 	}
-	filename := os.Args[1]
-	// Parse the file
-	colony, err := utils.ParseFile(filename)
+	if err != nil || flags.NArg() != 1 || (*strict && *lenient) || (*errorFormat != "text" && *errorFormat != "json") || (*byAnt && (*movesOnly || *noEcho)) {
+		return report.usage(usageLine, batchUsageLine, analyzeUsageLine, curveUsageLine, suggestUsageLine, renderUsageLine, lspUsageLine)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			code = report.fail(fmt.Errorf("%w: %v", errInternal, r))
+		}
+	}()
+
+	profiling, err := startProfiling(*cpuProfile, *memProfile)
 	if err != nil {
-		fmt.Println("ERROR: invalid data format,", err)
-		return
+		return report.fail(err)
+	}
+	defer func() {
+		if err := profiling.stop(); err != nil && code == exitOK {
+			code = report.fail(err)
+		}
+	}()
+	var stats runStats
+	allocations := measureAllocations()
+
+	opts := lemin.ParseOptions{Mode: parseMode(*strict, *lenient), AllErrors: *allErrors}
+
+	start := time.Now()
+	colony, err := readColony(flags.Arg(0), stdin, opts)
+	stats.Parse = time.Since(start)
+	if err != nil {
+		return report.fail(err)
 	}
 
 	// Find paths and determine moves
-	paths, antsPerPath, turns := utils.FindPaths(colony)
-	moves := utils.MoveAnts(paths, antsPerPath, turns)
+	solveOpts := lemin.SolveOptions{Stats: &stats.Stats, Reduce: *reduce}
+	if *explain {
+		solveOpts.Trace = slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	if *tracePath != "" {
+		trace, closeTrace, err := openTrace(*tracePath, stderr)
+		if err != nil {
+			return report.fail(err)
+		}
+		defer closeTrace()
+		solveOpts.Trace = trace
+	}
+	solution, err := solve(colony, solveOpts, *timeout)
+	if err != nil {
+		return report.fail(err)
+	}
 
-	// Print the file contents
-	fmt.Println(resources.FileContents)
+	// Print the file contents and stream the moves turn by turn
+	out, closeOutput, err := openOutput(*output, stdout)
+	if err != nil {
+		return report.fail(err)
+	}
+	defer func() {
+		if err := closeOutput(); err != nil && code == exitOK {
+			code = report.fail(err)
+		}
+	}()
+	if *byAnt {
+		err = writeItineraries(out, solution)
+	} else {
+		err = lemin.Format(out, colony, solution, lemin.FormatOptions{MovesOnly: *movesOnly, NoEcho: *noEcho, Stats: &stats.Stats})
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+
+	if *showStats {
+		stats.Allocations, stats.Allocated = allocations()
+		if err := writeStats(stderr, stats); err != nil {
+			return report.fail(err)
+		}
+	}
+	return exitOK
+}
+
+// readColony parses the named map file, or standard input for "-".
+func readColony(filename string, stdin io.Reader, opts lemin.ParseOptions) (*lemin.Colony, error) {
+	if filename == "-" {
+		return lemin.Parse(stdin, opts)
+	}
+	return lemin.ParseFile(filename, opts)
+}
 
-	// Print the moves
-	for _, move := range moves {
-		fmt.Println(move)
+// openOutput creates the named output file, or returns stdout when path is empty.
+func openOutput(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" {
+		return stdout, func() error { return nil }, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file.Close, nil
+}
+
+// parseMode returns the parse mode selected by the --strict and --lenient flags.
+func parseMode(strict, lenient bool) lemin.ParseMode {
+	switch {
+	case strict:
+		return lemin.ParseStrict
+	case lenient:
+		return lemin.ParseLenient
+	}
+	return lemin.ParseDefault
+}
+
+// openTrace returns a logger writing JSON lines to the named file, or to stderr for "-".
+func openTrace(path string, stderr io.Writer) (*slog.Logger, func(), error) {
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if path == "-" {
+		return slog.New(slog.NewJSONHandler(stderr, handlerOpts)), func() {}, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
 	}
+	return slog.New(slog.NewJSONHandler(file, handlerOpts)), func() { file.Close() }, nil
 }
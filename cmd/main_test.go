@@ -0,0 +1,605 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"lem-in/internal/utils"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// corpusEntry describes one map of the end-to-end corpus in testdata/corpus.json.
+type corpusEntry struct {
+	File     string `json:"file"`
+	MaxTurns int    `json:"maxTurns"` // Most turns the solver may use
+	Error    string `json:"error"`    // Expected error message, empty for solvable maps
+	Exit     int    `json:"exit"`     // Expected exit code
+}
+
+func TestCorpus(t *testing.T) {
+	dir := filepath.Join("..", "testdata")
+	data, err := os.ReadFile(filepath.Join(dir, "corpus.json"))
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	var corpus []corpusEntry
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatalf("failed to decode corpus: %v", err)
+	}
+
+	for _, entry := range corpus {
+		t.Run(entry.File, func(t *testing.T) {
+			filename := filepath.Join(dir, entry.File)
+			// Errors go to stderr, the golden files hold both streams
+			var stdout bytes.Buffer
+			code := run([]string{filename}, nil, &stdout, &stdout)
+			// Included files are named relative to the corpus, whatever the working directory
+			got := strings.ReplaceAll(stdout.String(), dir+string(filepath.Separator), "")
+			if code != entry.Exit {
+				t.Errorf("exit code %d, want %d", code, entry.Exit)
+			}
+
+			golden := strings.TrimSuffix(filename, ".txt") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s, run go test ./cmd -update to accept it:\n%s", golden, got)
+			}
+
+			if entry.Error != "" {
+				if !strings.HasPrefix(got, "ERROR: invalid data format,") || !strings.Contains(got, entry.Error) {
+					t.Errorf("got %q, want error containing %q", got, entry.Error)
+				}
+				return
+			}
+
+			// The moves follow the echoed map and a blank line
+			_, moves, found := strings.Cut(got, "\n\n")
+			if !found {
+				t.Fatalf("no moves in output:\n%s", got)
+			}
+			turns := strings.Split(strings.TrimSuffix(moves, "\n"), "\n")
+			if len(turns) > entry.MaxTurns {
+				t.Errorf("solved in %d turns, want at most %d", len(turns), entry.MaxTurns)
+			}
+
+			colony, err := utils.ParseFile(filename)
+			if err != nil {
+				t.Fatalf("ParseFile() error = %v", err)
+			}
+			if err := utils.VerifyMoves(colony, turns); err != nil {
+				t.Errorf("invalid moves: %v", err)
+			}
+
+			// The solvable maps, included files too, also follow the strict format
+			var strict bytes.Buffer
+			if code := run([]string{"--strict", filename}, nil, &strict, &strict); code != exitOK || strict.String() != got {
+				t.Errorf("--strict: exit code %d, output:\n%s", code, strict.String())
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := filepath.Join("..", "testdata")
+	files := []string{
+		filepath.Join(dir, "example00.txt"),
+		filepath.Join(dir, "badexample00.txt"),
+		filepath.Join(dir, "missing.txt"),
+		filepath.Join(dir, "example0[12].txt"),
+	}
+	want := []batchResult{
+		{File: filepath.Join(dir, "example00.txt"), Status: "ok", Ants: 4, Rooms: 4, Links: 3, Paths: 1, Turns: 6},
+		{File: filepath.Join(dir, "badexample00.txt"), Status: "parse", Error: "line 1: number of ants must be positive"},
+		{File: filepath.Join(dir, "missing.txt"), Status: "io", Error: "error opening file"},
+		{File: filepath.Join(dir, "example01.txt"), Status: "ok", Ants: 10, Rooms: 14, Links: 17, Paths: 3, Turns: 8},
+		{File: filepath.Join(dir, "example02.txt"), Status: "ok", Ants: 20, Rooms: 4, Links: 4, Paths: 2, Turns: 11},
+	}
+
+	for _, jobs := range []string{"1", "8"} {
+		var stdout bytes.Buffer
+		code := run(append([]string{"batch", "-j", jobs, "--format", "json"}, files...), nil, &stdout, io.Discard)
+		if code != exitParse {
+			t.Errorf("-j %s: exit code %d, want %d for the first failed map", jobs, code, exitParse)
+		}
+		var got []batchResult
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("-j %s: invalid JSON summary: %v\n%s", jobs, err, stdout.String())
+		}
+		if len(got) != len(want) {
+			t.Fatalf("-j %s: got %d rows, want %d", jobs, len(got), len(want))
+		}
+		for i := range want {
+			if !strings.Contains(got[i].Error, want[i].Error) || (want[i].Error == "") != (got[i].Error == "") {
+				t.Errorf("-j %s: row %d error = %q, want %q", jobs, i, got[i].Error, want[i].Error)
+			}
+			got[i].Error, got[i].Millis = want[i].Error, 0
+			if got[i] != want[i] {
+				t.Errorf("-j %s: row %d = %+v, want %+v", jobs, i, got[i], want[i])
+			}
+		}
+	}
+
+	var stdout bytes.Buffer
+	run([]string{"batch", "--format", "csv", files[0], files[1]}, nil, &stdout, io.Discard)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || lines[0] != "file,status,ants,rooms,links,paths,turns,time,error" {
+		t.Errorf("unexpected CSV summary:\n%s", stdout.String())
+	}
+}
+
+func TestInputOutput(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join("..", "testdata", "example00.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(input)
+	zw.Close()
+	golden, err := os.ReadFile(filepath.Join("..", "testdata", "example00.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, moves, _ := strings.Cut(string(golden), "\n\n")
+
+	tests := []struct {
+		name  string
+		args  []string
+		stdin []byte
+		want  string
+	}{
+		{name: "stdin", args: []string{"-"}, stdin: input, want: string(golden)},
+		{name: "gzip stdin", args: []string{"-"}, stdin: compressed.Bytes(), want: string(golden)},
+		{name: "moves only", args: []string{"--moves-only", "-"}, stdin: input, want: moves},
+		{name: "no echo", args: []string{"--no-echo", "-"}, stdin: input, want: "\n" + moves},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			run(tt.args, bytes.NewReader(tt.stdin), &stdout, io.Discard)
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	output := filepath.Join(t.TempDir(), "out.txt")
+	var stdout bytes.Buffer
+	run([]string{"--output", output, "-"}, bytes.NewReader(input), &stdout, io.Discard)
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("output file not written: %v", err)
+	}
+	if string(got) != string(golden) || stdout.Len() != 0 {
+		t.Errorf("--output wrote %q to the file and %q to stdout", got, stdout.String())
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := filepath.Join("..", "testdata")
+	tests := []struct {
+		name       string
+		args       []string
+		want       int
+		wantStderr string
+	}{
+		{name: "solved", args: []string{filepath.Join(dir, "example00.txt")}, want: exitOK},
+		{name: "no file", args: []string{}, want: exitUsage, wantStderr: "Usage:"},
+		{name: "unknown flag", args: []string{"--bogus", "map.txt"}, want: exitUsage, wantStderr: "Usage:"},
+		{name: "bad error format", args: []string{"--errors", "xml", "map.txt"}, want: exitUsage, wantStderr: "Usage:"},
+		{name: "missing file", args: []string{filepath.Join(dir, "missing.txt")}, want: exitIO, wantStderr: "ERROR: error opening file"},
+		{name: "parse", args: []string{filepath.Join(dir, "badexample00.txt")}, want: exitParse, wantStderr: "ERROR: invalid data format, line 1"},
+		{name: "unsolvable", args: []string{filepath.Join(dir, "badexample01.txt")}, want: exitUnsolvable, wantStderr: "no path"},
+		{name: "timeout", args: []string{"--timeout", "1ns", filepath.Join(dir, "big_corridors.txt")}, want: exitTimeout, wantStderr: "timed out"},
+		{name: "batch usage", args: []string{"batch"}, want: exitUsage, wantStderr: "Usage:"},
+		{
+			name:       "json",
+			args:       []string{"--errors", "json", filepath.Join(dir, "badexample05.txt")},
+			want:       exitParse,
+			wantStderr: `{"category":"parse","code":4,"message":"line 9: room does not exist: ghost","errors":[{"line":9,"message":"room does not exist: ghost"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, nil, &stdout, &stderr); code != tt.want {
+				t.Errorf("exit code %d, want %d; stderr:\n%s", code, tt.want, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+			if tt.want != exitOK && stdout.Len() != 0 {
+				t.Errorf("failure wrote to stdout: %q", stdout.String())
+			}
+		})
+	}
+
+	// Only errors known to come from the file system are I/O failures
+	if code := exitCode(errors.New("unexpected")); code != exitInternal {
+		t.Errorf("exit code of an unknown error %d, want %d", code, exitInternal)
+	}
+}
+
+func TestStatsAndProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cpu, mem := filepath.Join(dir, "cpu.pprof"), filepath.Join(dir, "mem.pprof")
+	var stdout, stderr bytes.Buffer
+	args := []string{"--stats", "--cpuprofile", cpu, "--memprofile", mem, filepath.Join("..", "testdata", "example01.txt")}
+	if code := run(args, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+
+	for _, want := range []string{"parse", "search", "assign", "schedule", "output", "bfs queue peak", "paths enumerated  9", "augmenting paths", "allocations", "reduce", "graph             14 rooms, 17 tunnels -> 14 rooms, 17 tunnels"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stats missing %q:\n%s", want, stderr.String())
+		}
+	}
+	for _, profile := range []string{cpu, mem} {
+		if info, err := os.Stat(profile); err != nil || info.Size() == 0 {
+			t.Errorf("profile %s not written: %v", profile, err)
+		}
+	}
+
+	// --reduce searches the pruned and contracted map, which breaks the tie
+	// between the two five-room paths the other way
+	stdout.Reset()
+	stderr.Reset()
+	args = []string{"--reduce", "--stats", "--moves-only", filepath.Join("..", "testdata", "example01.txt")}
+	if code := run(args, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("--reduce: exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	if want := "graph             14 rooms, 17 tunnels -> 5 rooms, 8 tunnels"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("--reduce stats missing %q:\n%s", want, stderr.String())
+	}
+	if want := "L1-h L2-0 L4-t\n"; !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("--reduce moves start %q, want %q", strings.SplitAfter(stdout.String(), "\n")[0], want)
+	}
+}
+
+func TestLSP(t *testing.T) {
+	uri := "file:///tmp/colony.txt"
+	text := "2\n##start\nstart 0 0\nmid 1 1\n##end\nend 2 0\nstart-mid\nmid-end\nmid-\n"
+	var session []string
+	send := func(id int, method string, params any) {
+		msg := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+		if id > 0 {
+			msg["id"] = id
+		}
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session = append(session, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body))
+	}
+	at := func(line, character int) map[string]any {
+		return map[string]any{"textDocument": map[string]string{"uri": uri}, "position": map[string]int{"line": line, "character": character}}
+	}
+	send(1, "initialize", map[string]any{})
+	send(0, "initialized", map[string]any{})
+	// Diagnostics wait for the edits to settle, so only the changed text is checked
+	valid := strings.TrimSuffix(text, "mid-\n")
+	send(0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "lem-in", "version": 1, "text": valid}})
+	send(0, "textDocument/didChange", map[string]any{"textDocument": map[string]any{"uri": uri, "version": 2}, "contentChanges": []map[string]string{{"text": text}}})
+	// Problems in an included file are shown on the ##include line
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.map"), []byte("a 5 5\na-nowhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	includeURI := "file://" + filepath.ToSlash(filepath.Join(dir, "main.txt"))
+	includeText := "1\n##start\ns 0 0\n##end\ne 1 0\n##include broken.map\ns-e\n"
+	send(0, "textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": includeURI, "languageId": "lem-in", "version": 1, "text": includeText}})
+	send(2, "textDocument/definition", at(7, 1))
+	send(3, "textDocument/hover", at(6, 7))
+	rename := at(3, 0)
+	rename["newName"] = "hub"
+	send(4, "textDocument/rename", rename)
+	send(5, "textDocument/completion", at(8, 4))
+	send(6, "textDocument/formatting", at(0, 0))
+	send(7, "shutdown", nil)
+	send(0, "exit", nil)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"lsp"}, strings.NewReader(strings.Join(session, "")), &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+
+	responses := make(map[int]string)
+	var diagnostics []string
+	for rest := stdout.String(); rest != ""; {
+		header, body, found := strings.Cut(rest, "\r\n\r\n")
+		if !found {
+			t.Fatalf("unframed output: %q", rest)
+		}
+		length, err := strconv.Atoi(strings.TrimPrefix(header, "Content-Length: "))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg struct {
+			ID     int             `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		if err := json.Unmarshal([]byte(body[:length]), &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Method == "textDocument/publishDiagnostics" {
+			diagnostics = append(diagnostics, string(msg.Params))
+		} else {
+			responses[msg.ID] = body[:length]
+		}
+		rest = body[length:]
+	}
+
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{1, `"renameProvider":true`},
+		{2, `"result":{"uri":"file:///tmp/colony.txt","range":{"start":{"line":3,"character":0},"end":{"line":3,"character":3}}}`},
+		{3, `**mid** at (1, 1), 2 links`},
+		{4, `{"range":{"start":{"line":7,"character":0},"end":{"line":7,"character":3}},"newText":"hub"}`},
+		{5, `"label":"end"`},
+		{6, `"code":-32601`},
+		{7, `"result":null`},
+	}
+	for _, test := range tests {
+		if !strings.Contains(responses[test.id], test.want) {
+			t.Errorf("response %d = %s, want it to contain %s", test.id, responses[test.id], test.want)
+		}
+	}
+	var main, included []string
+	for _, d := range diagnostics {
+		if strings.Contains(d, includeURI) {
+			included = append(included, d)
+		} else {
+			main = append(main, d)
+		}
+	}
+	if len(main) != 1 || !strings.Contains(main[0], `"line":8`) || !strings.Contains(main[0], `"severity":1`) {
+		t.Errorf("diagnostics = %v, want one error on line 8", main)
+	}
+	if len(included) != 1 || !strings.Contains(included[0], `"line":5`) || !strings.Contains(included[0], `broken.map: line 2: room does not exist: nowhere`) {
+		t.Errorf("diagnostics = %v, want the broken.map error on line 5", included)
+	}
+}
+
+func TestCurve(t *testing.T) {
+	dir := filepath.Join("..", "testdata")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"curve", filepath.Join(dir, "example01.txt")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	rows := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if rows[0] != "ants,turns,paths,lengths,set,threshold" || len(rows) != 11 {
+		t.Fatalf("curve CSV has %d rows starting with %q, want a header and 10 rows", len(rows), rows[0])
+	}
+
+	// The last row is the colony's own ant count, which must match a normal run
+	stdout.Reset()
+	if code := run([]string{"--moves-only", filepath.Join(dir, "example01.txt")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	turns := strings.Count(stdout.String(), "\n")
+	if want := "10," + strconv.Itoa(turns) + ","; !strings.HasPrefix(rows[10], want) {
+		t.Errorf("curve row %q, want it to start with %q", rows[10], want)
+	}
+
+	svg := filepath.Join(t.TempDir(), "curve.svg")
+	if code := run([]string{"curve", "--max", "40", "--format", "svg", "--output", svg, filepath.Join(dir, "example01.txt")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(svg); err != nil || !strings.Contains(string(data), "<polyline") || !strings.Contains(string(data), "3 paths from") {
+		t.Errorf("curve SVG = %.200s, %v", data, err)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"curve", filepath.Join(dir, "badexample01.txt")}, nil, &stdout, &stderr); code != exitUnsolvable {
+		t.Errorf("unsolvable map: exit code %d, stderr:\n%s", code, stderr.String())
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	dot, svg := filepath.Join(dir, "colony.dot"), filepath.Join(dir, "colony.svg")
+	var stdout, stderr bytes.Buffer
+	args := []string{"analyze", "--dot", dot, "--svg", svg, filepath.Join("..", "testdata", "example01.txt")}
+	if code := run(args, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	for _, want := range []string{"bottlenecks: 0, t, h\n", "at most 3 paths", "end   10    5-8"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("analyze report missing %q:\n%s", want, stdout.String())
+		}
+	}
+	for _, export := range []string{dot, svg} {
+		if data, err := os.ReadFile(export); err != nil || strings.Count(string(data), "#ff8c00") != 3 {
+			t.Errorf("%s does not highlight the 3 bottlenecks: %v", export, err)
+		}
+	}
+
+	stdout.Reset()
+	if code := run([]string{"analyze", "--format", "json", filepath.Join("..", "testdata", "example01.txt")}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	var analysis struct {
+		Cut   []string `json:"cut"`
+		Rooms []struct {
+			Name  string `json:"name"`
+			Ants  int    `json:"ants"`
+			Turns []int  `json:"turns"`
+		} `json:"rooms"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &analysis); err != nil || len(analysis.Cut) != 3 || analysis.Rooms[0].Ants != 3 {
+		t.Errorf("analyze JSON = %s, %v", stdout.String(), err)
+	}
+}
+
+func TestTurnRanges(t *testing.T) {
+	tests := []struct {
+		turns []int
+		want  string
+	}{
+		{nil, "-"},
+		{[]int{3}, "3"},
+		{[]int{1, 2, 3, 4, 6}, "1-4, 6"},
+		{[]int{2, 2, 3, 5, 5}, "2-3, 5"},
+	}
+	for _, test := range tests {
+		if got := turnRanges(test.turns); got != test.want {
+			t.Errorf("turnRanges(%v) = %q, want %q", test.turns, got, test.want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	dir := t.TempDir()
+	colony := filepath.Join(dir, "colony.txt")
+	candidates := filepath.Join(dir, "candidates.txt")
+	if err := os.WriteFile(colony, []byte("10\n##start\ns 0 0\na 1 0\nx 1 1\n##end\ne 2 0\ns-a\na-e\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(candidates, []byte("# new route\ns-x\nx-e\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"suggest", "--candidates", candidates, "-k", "2", colony}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	for _, want := range []string{"10 ants need 11 turns", "none of the 2 candidate tunnels", "best set of up to 2 tunnels: s-x x-e, 6 turns, 5 saved"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("suggest report missing %q:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := run([]string{"suggest", "--distance", "1.5", "-k", "2", "--format", "json", colony}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	var suggestions struct {
+		Tunnels []json.RawMessage `json:"tunnels"`
+		Best    struct {
+			Turns int `json:"turns"`
+		} `json:"best"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &suggestions); err != nil || len(suggestions.Tunnels) != 3 || suggestions.Best.Turns != 6 {
+		t.Errorf("suggest --distance JSON = %s, %v", stdout.String(), err)
+	}
+
+	if err := os.WriteFile(candidates, []byte("s-x\na-e\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stderr.Reset()
+	if code := run([]string{"suggest", "--candidates", candidates, colony}, nil, &stdout, &stderr); code != exitParse || !strings.Contains(stderr.String(), "line 2: duplicate room connection: a-e") {
+		t.Errorf("existing tunnel as candidate: exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	if code := run([]string{"suggest", colony}, nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("no candidates: exit code %d, want %d", code, exitUsage)
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	example := filepath.Join("..", "testdata", "example01.txt")
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"png", []string{"render", "--png", filepath.Join(dir, "map.png"), example}, exitOK},
+		{"options", []string{"render", "--png", filepath.Join(dir, "small.png"), "--size", "200", "--no-labels", "--hide-unused", example}, exitOK},
+		{"gif", []string{"render", "--gif", filepath.Join(dir, "moves.gif"), "--delay", "100ms", "--frames-per-turn", "3", example}, exitOK},
+		{"no image", []string{"render", example}, exitUsage},
+		{"both on stdout", []string{"render", "--png", "-", "--gif", "-", example}, exitUsage},
+		{"no frames", []string{"render", "--gif", filepath.Join(dir, "bad.gif"), "--frames-per-turn", "0", example}, exitUsage},
+		{"bad size", []string{"render", "--png", filepath.Join(dir, "bad.png"), "--size", "0", example}, exitUsage},
+		{"unsolvable", []string{"render", "--png", filepath.Join(dir, "none.png"), filepath.Join("..", "testdata", "badexample01.txt")}, exitUnsolvable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, nil, &stdout, &stderr); code != tt.code {
+				t.Fatalf("exit code %d, want %d, stderr:\n%s", code, tt.code, stderr.String())
+			}
+		})
+	}
+
+	for name, longest := range map[string]int{"map.png": 1000, "small.png": 200} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if side := max(config.Width, config.Height); side > longest+80 {
+			t.Errorf("%s is %d×%d, want the drawing within %d pixels", name, config.Width, config.Height, longest)
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"render", "--png", "-", example}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	if _, err := png.Decode(&stdout); err != nil {
+		t.Errorf("render --png - wrote no PNG to stdout: %v", err)
+	}
+
+	// 8 turns of 3 frames after the first frame
+	file, err := os.Open(filepath.Join(dir, "moves.gif"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	anim, err := gif.DecodeAll(file)
+	if err != nil || len(anim.Image) != 25 || anim.Delay[0] != 10 {
+		t.Errorf("render --gif wrote %d frames with delay %v, want 25 of 10: %v", len(anim.Image), anim.Delay, err)
+	}
+}
+
+func TestByAnt(t *testing.T) {
+	example := filepath.Join("..", "testdata", "example00.txt")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--by-ant", example}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("exit code %d, stderr:\n%s", code, stderr.String())
+	}
+	want := "ant  path  depart  finish  turns  route\n" +
+		"1    1     1       3       3      1:2 2:3 3:1\n" +
+		"2    1     2       4       3      2:2 3:3 4:1\n" +
+		"3    1     3       5       3      3:2 4:3 5:1\n" +
+		"4    1     4       6       3      4:2 5:3 6:1\n" +
+		"\n4 ants, 3.00 turns in the colony on average, 3 at most (ant 1)\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("--by-ant output = %q, want %q", got, want)
+	}
+
+	for _, conflict := range []string{"--moves-only", "--no-echo"} {
+		if code := run([]string{"--by-ant", conflict, example}, nil, io.Discard, io.Discard); code != exitUsage {
+			t.Errorf("--by-ant %s: exit code %d, want %d", conflict, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"text/tabwriter"
+	"time"
+
+	"lem-in"
+)
+
+// profiler writes the CPU and memory profiles asked for on the command line.
+type profiler struct {
+	cpu     *os.File
+	memPath string
+}
+
+// startProfiling starts the CPU profile when cpuPath is set. The memory
+// profile is written to memPath by stop.
+func startProfiling(cpuPath, memPath string) (*profiler, error) {
+	p := &profiler{memPath: memPath}
+	if cpuPath == "" {
+		return p, nil
+	}
+	file, err := os.Create(cpuPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	p.cpu = file
+	return p, nil
+}
+
+// stop ends the CPU profile and writes the memory profile.
+func (p *profiler) stop() error {
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		if err := p.cpu.Close(); err != nil {
+			return err
+		}
+	}
+	if p.memPath == "" {
+		return nil
+	}
+	file, err := os.Create(p.memPath)
+	if err != nil {
+		return err
+	}
+	runtime.GC() // Up to date statistics
+	if err := pprof.WriteHeapProfile(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runStats are the figures printed by --stats.
+type runStats struct {
+	lemin.Stats
+	Parse       time.Duration
+	Allocations uint64 // Heap objects allocated
+	Allocated   uint64 // Bytes allocated
+}
+
+// measureAllocations returns a function reporting the heap allocations made since the call.
+func measureAllocations() func() (uint64, uint64) {
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	return func() (uint64, uint64) {
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		return after.Mallocs - before.Mallocs, after.TotalAlloc - before.TotalAlloc
+	}
+}
+
+func writeStats(w io.Writer, s runStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "parse\t%v\n", s.Parse)
+	fmt.Fprintf(tw, "reduce\t%v\n", s.Reduce)
+	fmt.Fprintf(tw, "search\t%v\n", s.Search)
+	fmt.Fprintf(tw, "assign\t%v\n", s.Assign)
+	fmt.Fprintf(tw, "schedule\t%v\n", s.Schedule)
+	fmt.Fprintf(tw, "output\t%v\n", s.Output)
+	fmt.Fprintf(tw, "graph\t%d rooms, %d tunnels -> %d rooms, %d tunnels\n", s.Rooms, s.Tunnels, s.ReducedRooms, s.ReducedTunnels)
+	fmt.Fprintf(tw, "bfs queue peak\t%d\n", s.QueuePeak)
+	fmt.Fprintf(tw, "paths enumerated\t%d\n", s.Paths)
+	fmt.Fprintf(tw, "augmenting paths\t%d\n", s.Augmentations)
+	fmt.Fprintf(tw, "allocations\t%d (%d bytes)\n", s.Allocations, s.Allocated)
+	return tw.Flush()
+}
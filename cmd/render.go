@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"time"
+
+	"lem-in"
+)
+
+const renderUsage = "Usage: go run main.go render (--png path | --gif path) [--delay d] [--frames-per-turn N] [--size N] [--no-labels] [--hide-unused] [--timeout d] [--strict | --lenient] file.txt|-"
+
+// runRender draws the colony and the solver's paths as an image, or animates
+// the ants moving along them.
+func runRender(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lem-in render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	pngPath := flags.String("png", "", "write a PNG image to this file, - for stdout")
+	gifPath := flags.String("gif", "", "write an animated GIF to this file, - for stdout")
+	delay := flags.Duration("delay", 250*time.Millisecond, "how long each frame of the GIF is shown")
+	framesPerTurn := flags.Int("frames-per-turn", 1, "GIF frames drawn for each turn, more for smoother movement")
+	size := flags.Int("size", 1000, "longest side of the drawing in pixels")
+	noLabels := flags.Bool("no-labels", false, "leave out the room names")
+	hideUnused := flags.Bool("hide-unused", false, "leave out the rooms no path visits")
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, 0 for no limit")
+	strict := flags.Bool("strict", false, "parse the map in strict mode")
+	lenient := flags.Bool("lenient", false, "parse the map in lenient mode")
+	report := reporter{w: stderr}
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil || flags.NArg() != 1 || (*pngPath == "" && *gifPath == "") || (*pngPath == "-" && *gifPath == "-") ||
+		*size <= 0 || *delay <= 0 || *framesPerTurn < 1 || (*strict && *lenient) {
+		return report.usage(renderUsage)
+	}
+
+	colony, err := readColony(flags.Arg(0), stdin, lemin.ParseOptions{Mode: parseMode(*strict, *lenient)})
+	if err != nil {
+		return report.fail(err)
+	}
+	solution, err := solve(colony, lemin.SolveOptions{}, *timeout)
+	if err != nil {
+		return report.fail(err)
+	}
+
+	opts := lemin.RenderOptions{Solution: solution, Size: *size, NoLabels: *noLabels, HideUnused: *hideUnused}
+	if *pngPath != "" {
+		err := writeImage(*pngPath, stdout, func(w io.Writer) error {
+			return lemin.WritePNG(w, colony, opts)
+		})
+		if err != nil {
+			return report.fail(err)
+		}
+	}
+	if *gifPath != "" {
+		err := writeImage(*gifPath, stdout, func(w io.Writer) error {
+			return lemin.WriteGIF(w, colony, lemin.AnimationOptions{RenderOptions: opts, Delay: *delay, FramesPerTurn: *framesPerTurn})
+		})
+		if err != nil {
+			return report.fail(err)
+		}
+	}
+	return exitOK
+}
+
+// writeImage writes an image to the named file, or to stdout for "-".
+func writeImage(path string, stdout io.Writer, write func(io.Writer) error) error {
+	if path == "-" {
+		path = ""
+	}
+	out, closeOutput, err := openOutput(path, stdout)
+	if err != nil {
+		return err
+	}
+	if err := write(out); err != nil {
+		closeOutput()
+		return err
+	}
+	return closeOutput()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"lem-in"
+)
+
+const suggestUsage = "Usage: go run main.go suggest (--distance d | --candidates file) [-k N] [--top N] [--format text|json] [--strict | --lenient] file.txt|-"
+
+// runSuggest reports which new tunnels most reduce the turns the map needs.
+func runSuggest(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lem-in suggest", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	distance := flags.Float64("distance", 0, "consider tunnels between rooms at most this far apart")
+	candidatesPath := flags.String("candidates", "", "consider the tunnels listed in this file, one link line each")
+	k := flags.Int("k", 1, "most tunnels added together")
+	top := flags.Int("top", 10, "number of single tunnels listed")
+	format := flags.String("format", "text", "report format: text or json")
+	strict := flags.Bool("strict", false, "parse the map in strict mode")
+	lenient := flags.Bool("lenient", false, "parse the map in lenient mode")
+	report := reporter{w: stderr}
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil || flags.NArg() != 1 || *k < 1 || *top < 0 || (*strict && *lenient) || (*format != "text" && *format != "json") {
+		return report.usage(suggestUsage)
+	}
+	if (*distance > 0) == (*candidatesPath != "") {
+		return report.usage(suggestUsage)
+	}
+
+	colony, err := readColony(flags.Arg(0), stdin, lemin.ParseOptions{Mode: parseMode(*strict, *lenient)})
+	if err != nil {
+		return report.fail(err)
+	}
+	opts := lemin.SuggestOptions{Distance: *distance, Max: *k}
+	if *candidatesPath != "" {
+		if opts.Candidates, err = readCandidates(*candidatesPath, colony); err != nil {
+			return report.fail(err)
+		}
+	}
+	suggestions, err := lemin.Suggest(colony, opts)
+	if errors.Is(err, lemin.ErrInvalidCandidate) {
+		return report.usage("ERROR: "+err.Error(), suggestUsage)
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(suggestions)
+	} else {
+		err = writeSuggestions(stdout, colony, suggestions, *k, *top)
+	}
+	if err != nil {
+		return report.fail(err)
+	}
+	return exitOK
+}
+
+// readCandidates reads the candidate tunnels from a file of link lines. Empty
+// lines and # comments are skipped.
+func readCandidates(path string, colony *lemin.Colony) ([]lemin.Link, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Tunnels the map or the file already has are rejected like duplicate links in a map
+	seen := make(map[lemin.Link]bool, len(colony.Links))
+	for _, link := range colony.Links {
+		seen[link], seen[lemin.Link{From: link.To, To: link.From}] = true, true
+	}
+	var links []lemin.Link
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		link, err := lemin.ParseLink(colony, line)
+		if err == nil && seen[link] {
+			err = fmt.Errorf("duplicate room connection: %s", link)
+		}
+		if err != nil {
+			return nil, &lemin.ParseError{File: path, Line: number, Err: err}
+		}
+		seen[link], seen[lemin.Link{From: link.To, To: link.From}] = true, true
+		links = append(links, link)
+	}
+	return links, scanner.Err()
+}
+
+// writeSuggestions writes the best single tunnels and the best set of tunnels.
+func writeSuggestions(w io.Writer, colony *lemin.Colony, s *lemin.Suggestions, k, top int) error {
+	fmt.Fprintf(w, "%d ants need %d turns\n\n", colony.Ants, s.Turns)
+
+	helpful := 0
+	for _, tunnel := range s.Tunnels {
+		if tunnel.Saved > 0 {
+			helpful++
+		}
+	}
+	if helpful == 0 {
+		fmt.Fprintf(w, "none of the %d candidate tunnels saves a turn on its own\n", len(s.Tunnels))
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "tunnel\tturns\tsaved")
+		for _, tunnel := range s.Tunnels[:min(top, helpful)] {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", formatLinks(tunnel.Links), tunnel.Turns, tunnel.Saved)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if k > 1 {
+		method := "every combination tried"
+		if !s.Exhaustive {
+			method = "built greedily"
+		}
+		fmt.Fprintln(w)
+		if len(s.Best.Links) == 0 {
+			_, err := fmt.Fprintf(w, "no set of up to %d tunnels saves a turn (%s)\n", k, method)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "best set of up to %d tunnels: %s, %d turns, %d saved (%s)\n", k, formatLinks(s.Best.Links), s.Best.Turns, s.Best.Saved, method)
+		return err
+	}
+	return nil
+}
+
+// formatLinks writes links as link lines separated by spaces.
+func formatLinks(links []lemin.Link) string {
+	lines := make([]string, len(links))
+	for i, link := range links {
+		lines[i] = link.String()
+	}
+	return strings.Join(lines, " ")
+}
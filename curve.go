@@ -0,0 +1,130 @@
+package lemin
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"lem-in/internal/utils"
+)
+
+// CurvePoint is the solution for one number of ants.
+type CurvePoint struct {
+	Ants  int        `json:"ants"`
+	Turns int        `json:"turns"`
+	Set   string     `json:"set"`   // The solver's candidate set: OptimizedPaths1, OptimizedPaths2 or disjoint
+	Paths [][]string `json:"paths"` // Room names of the paths carrying ants
+	// Threshold is set when the ants use more paths than with one ant fewer,
+	// where adding a path starts to pay off.
+	Threshold bool `json:"threshold"`
+}
+
+// Curve solves the colony for every number of ants from 1 to maxAnts, giving
+// the same turns and paths as Solve would for each. The paths are only
+// searched once, so this is much faster than maxAnts calls to Solve.
+func Curve(c *Colony, maxAnts int) ([]CurvePoint, error) {
+	if maxAnts < 1 {
+		return nil, fmt.Errorf("invalid number of ants: %d", maxAnts)
+	}
+	internal := utils.Curve(c.antColony(), maxAnts)
+	if internal == nil {
+		return nil, ErrNoPath
+	}
+
+	points := make([]CurvePoint, len(internal))
+	for i, point := range internal {
+		points[i] = CurvePoint{Ants: point.Ants, Turns: point.Turns, Set: point.Set}
+		for _, path := range point.Paths {
+			points[i].Paths = append(points[i].Paths, path.RoomsInThePath)
+		}
+		points[i].Threshold = i > 0 && len(point.Paths) > len(internal[i-1].Paths)
+	}
+	return points, nil
+}
+
+// WriteCurveCSV writes one row per point with the columns ants, turns, paths,
+// lengths, set and threshold. lengths lists the moves along each path used.
+func WriteCurveCSV(w io.Writer, points []CurvePoint) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"ants", "turns", "paths", "lengths", "set", "threshold"})
+	for _, point := range points {
+		cw.Write([]string{
+			strconv.Itoa(point.Ants),
+			strconv.Itoa(point.Turns),
+			strconv.Itoa(len(point.Paths)),
+			pathLengths(point.Paths),
+			point.Set,
+			strconv.FormatBool(point.Threshold),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCurveSVG draws the turns against the number of ants, marking each
+// threshold with the number of paths used from there on.
+func WriteCurveSVG(w io.Writer, points []CurvePoint) error {
+	const width, height, margin = 800, 400, 50
+	bw := bufio.NewWriter(w)
+	maxAnts, maxTurns := 1, 1
+	for _, point := range points {
+		maxAnts, maxTurns = max(maxAnts, point.Ants), max(maxTurns, point.Turns)
+	}
+	x := func(ants int) int {
+		if maxAnts == 1 {
+			return margin
+		}
+		return margin + (ants-1)*(width-2*margin)/(maxAnts-1)
+	}
+	y := func(turns int) int {
+		return height - margin - turns*(height-2*margin)/maxTurns
+	}
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(bw, `<g font-family="sans-serif" font-size="12">`+"\n")
+
+	// Axes, labelled at both ends
+	fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", margin, height-margin, width-margin, height-margin)
+	fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", margin, margin, margin, height-margin)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">1</text>`+"\n", x(1), height-margin+16)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">%d</text>`+"\n", x(maxAnts), height-margin+16, maxAnts)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle">ants</text>`+"\n", width/2, height-margin+32)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">0</text>`+"\n", margin-6, y(0)+4)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="end">%d</text>`+"\n", margin-6, y(maxTurns)+4, maxTurns)
+	fmt.Fprintf(bw, `<text x="%d" y="%d" text-anchor="middle" transform="rotate(-90 %d %d)">turns</text>`+"\n", margin-30, height/2, margin-30, height/2)
+
+	for _, point := range points {
+		if !point.Threshold {
+			continue
+		}
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#999999" stroke-dasharray="4 4"/>`+"\n", x(point.Ants), margin, x(point.Ants), height-margin)
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%d paths from %d ants</text>`+"\n", x(point.Ants)+4, margin+12, len(point.Paths), point.Ants)
+	}
+
+	coords := make([]string, len(points))
+	for i, point := range points {
+		coords[i] = strconv.Itoa(x(point.Ants)) + "," + strconv.Itoa(y(point.Turns))
+	}
+	fmt.Fprintf(bw, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(coords, " "), pathColors[2])
+	for _, point := range points {
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="2" fill="%s"><title>%d ants: %d turns on %d paths (%s)</title></circle>`+"\n",
+			x(point.Ants), y(point.Turns), pathColors[2], point.Ants, point.Turns, len(point.Paths), pathLengths(point.Paths))
+	}
+
+	fmt.Fprintln(bw, "</g>")
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// pathLengths lists the number of moves along each path, separated by spaces.
+func pathLengths(paths [][]string) string {
+	lengths := make([]string, len(paths))
+	for i, path := range paths {
+		lengths[i] = strconv.Itoa(len(path) - 1)
+	}
+	return strings.Join(lengths, " ")
+}
@@ -0,0 +1,262 @@
+package lemin
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportOptions configures the JSON, DOT and SVG exports.
+type ExportOptions struct {
+	// Solution, when set, is included in the export and its paths are drawn in colour.
+	Solution *Solution
+	// Highlight names rooms to draw with a thick orange outline in the DOT and
+	// SVG exports, such as the bottlenecks found by Analyze.
+	Highlight []string
+}
+
+// highlightColor outlines the rooms in ExportOptions.Highlight.
+const highlightColor = "#ff8c00"
+
+// pathColors are the colours used for the paths of a solution, in order.
+var pathColors = []string{
+	"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4",
+	"#42d4f4", "#f032e6", "#bfef45", "#469990", "#9a6324",
+}
+
+// ExportJSON writes the colony, and the solution if any, as a JSON object with
+// the fields ants, start, end, rooms, links and solution.
+func ExportJSON(w io.Writer, c *Colony, opts ExportOptions) error {
+	out := struct {
+		*Colony
+		Solution *Solution `json:"solution,omitempty"`
+	}{c, opts.Solution}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// ExportDOT writes the colony as a Graphviz graph. Rooms are pinned to their
+// coordinates, the start and end rooms are drawn as double circles and room
+// metadata becomes node attributes, so a label or color annotation is used
+// by Graphviz directly.
+func ExportDOT(w io.Writer, c *Colony, opts ExportOptions) error {
+	bw := bufio.NewWriter(w)
+	pathEdges := solutionEdges(opts.Solution)
+	highlighted := nameSet(opts.Highlight)
+
+	fmt.Fprintln(bw, "graph colony {")
+	fmt.Fprintln(bw, "\tnode [shape=circle];")
+	for _, room := range c.Rooms {
+		attrs := map[string]string{
+			"pos": fmt.Sprintf("%d,%d!", room.X, -room.Y),
+		}
+		if room.Name == c.Start || room.Name == c.End {
+			attrs["shape"] = "doublecircle"
+		}
+		for key, value := range room.Meta {
+			attrs[key] = value
+		}
+		if highlighted[room.Name] {
+			attrs["color"], attrs["penwidth"] = highlightColor, "3"
+		}
+		fmt.Fprintf(bw, "\t%s [%s];\n", dotID(room.Name), dotAttrs(attrs))
+	}
+	for _, link := range c.Links {
+		if color, used := pathEdges[linkKey(link.From, link.To)]; used {
+			fmt.Fprintf(bw, "\t%s -- %s [color=%s, penwidth=3];\n", dotID(link.From), dotID(link.To), dotID(color))
+		} else {
+			fmt.Fprintf(bw, "\t%s -- %s;\n", dotID(link.From), dotID(link.To))
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// ExportSVG draws the colony as an SVG image with rooms at their coordinates.
+// A room's label and color metadata replace its name and fill colour, every
+// metadata value is kept as a data- attribute and shown in the room's tooltip.
+func ExportSVG(w io.Writer, c *Colony, opts ExportOptions) error {
+	layout := newLayout(c, 1000, 40)
+	pathEdges := solutionEdges(opts.Solution)
+	highlighted := nameSet(opts.Highlight)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		layout.width, layout.height, layout.width, layout.height)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	for _, link := range c.Links {
+		x1, y1 := layout.point(link.From)
+		x2, y2 := layout.point(link.To)
+		stroke, width := "#999999", 2
+		if color, used := pathEdges[linkKey(link.From, link.To)]; used {
+			stroke, width = color, 4
+		}
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="%d"/>`+"\n",
+			x1, y1, x2, y2, xmlEscape(stroke), width)
+	}
+
+	for _, room := range c.Rooms {
+		x, y := layout.point(room.Name)
+		fill := "#ffffff"
+		switch room.Name {
+		case c.Start:
+			fill = "#8fd18f"
+		case c.End:
+			fill = "#f08080"
+		}
+		if color, ok := room.Meta["color"]; ok {
+			fill = color
+		}
+		label := room.Name
+		if text, ok := room.Meta["label"]; ok {
+			label = text
+		}
+
+		class, stroke, strokeWidth := "room", "black", 1
+		if highlighted[room.Name] {
+			class, stroke, strokeWidth = "room highlight", highlightColor, 4
+		}
+
+		fmt.Fprintf(bw, `<g class="%s" data-name="%s"`, class, xmlEscape(room.Name))
+		for _, key := range sortedKeys(room.Meta) {
+			fmt.Fprintf(bw, ` data-%s="%s"`, dataAttr(key), xmlEscape(room.Meta[key]))
+		}
+		fmt.Fprintln(bw, ">")
+		fmt.Fprintf(bw, `<title>%s</title>`+"\n", xmlEscape(roomTitle(room)))
+		fmt.Fprintf(bw, `<circle cx="%d" cy="%d" r="8" fill="%s" stroke="%s" stroke-width="%d"/>`+"\n", x, y, xmlEscape(fill), stroke, strokeWidth)
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-family="sans-serif" font-size="12">%s</text>`+"\n", x+10, y-10, xmlEscape(label))
+		fmt.Fprintln(bw, "</g>")
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// layout maps room coordinates onto an image of at most size pixels per side.
+type layout struct {
+	minX, minY    int
+	scale         float64
+	margin        int
+	width, height int
+	rooms         map[string][2]int
+}
+
+func newLayout(c *Colony, size, margin int) *layout {
+	l := &layout{margin: margin, rooms: make(map[string][2]int, len(c.Rooms))}
+	if len(c.Rooms) == 0 {
+		l.width, l.height = 2*margin, 2*margin
+		return l
+	}
+
+	minX, minY, maxX, maxY := c.Rooms[0].X, c.Rooms[0].Y, c.Rooms[0].X, c.Rooms[0].Y
+	for _, room := range c.Rooms {
+		minX, maxX = min(minX, room.X), max(maxX, room.X)
+		minY, maxY = min(minY, room.Y), max(maxY, room.Y)
+	}
+	span := max(maxX-minX, maxY-minY, 1)
+	l.minX, l.minY = minX, minY
+	l.scale = min(float64(size)/float64(span), 60)
+	l.width = int(float64(maxX-minX)*l.scale) + 2*margin
+	l.height = int(float64(maxY-minY)*l.scale) + 2*margin
+
+	for _, room := range c.Rooms {
+		l.rooms[room.Name] = [2]int{
+			margin + int(float64(room.X-minX)*l.scale),
+			margin + int(float64(room.Y-minY)*l.scale),
+		}
+	}
+	return l
+}
+
+// point returns the pixel position of the named room.
+func (l *layout) point(name string) (int, int) {
+	p := l.rooms[name]
+	return p[0], p[1]
+}
+
+// solutionEdges maps each tunnel used by the solution to the colour of its path.
+func solutionEdges(s *Solution) map[string]string {
+	edges := make(map[string]string)
+	if s == nil {
+		return edges
+	}
+	for i, path := range s.Paths {
+		for j := 1; j < len(path); j++ {
+			edges[linkKey(path[j-1], path[j])] = pathColors[i%len(pathColors)]
+		}
+	}
+	return edges
+}
+
+// nameSet returns the names as a set.
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return set
+}
+
+// linkKey identifies a tunnel regardless of direction.
+func linkKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// roomTitle describes a room and its metadata for tooltips.
+func roomTitle(room Room) string {
+	title := fmt.Sprintf("%s (%d, %d)", room.Name, room.X, room.Y)
+	for _, key := range sortedKeys(room.Meta) {
+		title += "\n" + key + ": " + room.Meta[key]
+	}
+	return title
+}
+
+func dotID(s string) string {
+	return strconv.Quote(s)
+}
+
+func dotAttrs(attrs map[string]string) string {
+	parts := make([]string, 0, len(attrs))
+	for _, key := range sortedKeys(attrs) {
+		parts = append(parts, dotID(key)+"="+dotID(attrs[key]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dataAttr turns a metadata key into a valid data- attribute name.
+func dataAttr(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, key)
+}
+
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func xmlEscape(s string) string {
+	return xmlEscaper.Replace(s)
+}
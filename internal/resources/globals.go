@@ -0,0 +1,29 @@
+package resources
+
+type AntColony struct {
+	NumberOfAnts int
+	Rooms        []Room
+	Links        map[string][]string
+	Start        string
+	End          string
+	Coords       map[[2]int]string // Room names by coordinates, for duplicate checks
+	Tunnels      []Link            // Links in the order they were declared
+	Linked       map[Link]bool     // Declared tunnels in both directions, for duplicate checks
+	Source       string            // The kept input lines, echoed before the moves
+}
+type Room struct {
+	Name             string
+	IsVisited        bool
+	Coord_X, Coord_Y int
+	Directives       map[string]string // Arguments of the custom ## commands given before the room
+	Meta             map[string]string // Labels, tags and other values from #@ annotations
+}
+
+type Path struct {
+	RoomsInThePath []string
+}
+
+// Link is a tunnel between two rooms, used as an unambiguous key for room pairs.
+type Link struct {
+	From, To string
+}
@@ -0,0 +1,111 @@
+package resources
+
+import "sort"
+
+// Graph is an integer indexed view of an AntColony used by the solvers.
+// Rooms are numbered in declaration order and tunnels are stored in compressed
+// sparse row form: the neighbours of room i are Adjacency[Offsets[i]:Offsets[i+1]].
+type Graph struct {
+	NumberOfAnts int
+	Names        []string
+	X, Y         []int
+	Offsets      []int
+	Adjacency    []int
+	Start, End   int
+
+	ids    map[string]int
+	coords map[[2]int]int
+}
+
+// NewGraph interns the rooms of the colony and builds its adjacency.
+// Rooms that only appear in colony.Links are numbered after the declared ones.
+func NewGraph(colony *AntColony) *Graph {
+	g := &Graph{
+		NumberOfAnts: colony.NumberOfAnts,
+		ids:          make(map[string]int, len(colony.Links)),
+		coords:       make(map[[2]int]int, len(colony.Rooms)),
+	}
+	for _, room := range colony.Rooms {
+		if _, exists := g.ids[room.Name]; !exists {
+			g.addRoom(room.Name, room.Coord_X, room.Coord_Y)
+			g.coords[[2]int{room.Coord_X, room.Coord_Y}] = g.ids[room.Name]
+		}
+	}
+	var undeclared []string
+	for name := range colony.Links {
+		if _, exists := g.ids[name]; !exists {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, name := range undeclared {
+		g.addRoom(name, 0, 0)
+	}
+
+	g.Offsets = make([]int, len(g.Names)+1)
+	for i, name := range g.Names {
+		g.Offsets[i+1] = g.Offsets[i] + len(colony.Links[name])
+	}
+	g.Adjacency = make([]int, 0, g.Offsets[len(g.Names)])
+	for _, name := range g.Names {
+		for _, next := range colony.Links[name] {
+			id, exists := g.ids[next]
+			if !exists {
+				id = g.addRoom(next, 0, 0)
+			}
+			g.Adjacency = append(g.Adjacency, id)
+		}
+	}
+
+	g.Start, g.End = -1, -1
+	if id, exists := g.ids[colony.Start]; exists {
+		g.Start = id
+	}
+	if id, exists := g.ids[colony.End]; exists {
+		g.End = id
+	}
+	return g
+}
+
+func (g *Graph) addRoom(name string, x, y int) int {
+	id := len(g.Names)
+	g.ids[name] = id
+	g.Names = append(g.Names, name)
+	g.X = append(g.X, x)
+	g.Y = append(g.Y, y)
+	return id
+}
+
+// Len returns the number of rooms.
+func (g *Graph) Len() int {
+	return len(g.Names)
+}
+
+// Neighbors returns the rooms connected to the room by a tunnel.
+func (g *Graph) Neighbors(id int) []int {
+	if id+1 >= len(g.Offsets) {
+		return nil // Linked to but never declared, so it has no tunnels of its own
+	}
+	return g.Adjacency[g.Offsets[id]:g.Offsets[id+1]]
+}
+
+// ID returns the number of the named room.
+func (g *Graph) ID(name string) (int, bool) {
+	id, exists := g.ids[name]
+	return id, exists
+}
+
+// RoomAt returns the room declared at the given coordinates.
+func (g *Graph) RoomAt(x, y int) (int, bool) {
+	id, exists := g.coords[[2]int{x, y}]
+	return id, exists
+}
+
+// PathNames converts a path of room numbers back to a Path of room names.
+func (g *Graph) PathNames(ids []int) Path {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = g.Names[id]
+	}
+	return Path{RoomsInThePath: names}
+}
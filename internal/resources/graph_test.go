@@ -0,0 +1,96 @@
+package resources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewGraph(t *testing.T) {
+	colony := &AntColony{
+		NumberOfAnts: 2,
+		Start:        "start",
+		End:          "end",
+		Rooms: []Room{
+			{Name: "start", Coord_X: 0, Coord_Y: 0},
+			{Name: "a", Coord_X: 1, Coord_Y: 0},
+			{Name: "end", Coord_X: 2, Coord_Y: 0},
+		},
+		Links: map[string][]string{
+			"start": {"a", "end"},
+			"a":     {"start", "end"},
+			"end":   {"a", "start"},
+		},
+	}
+	g := NewGraph(colony)
+
+	if g.Len() != 3 || g.Start != 0 || g.End != 2 {
+		t.Fatalf("NewGraph() = %d rooms, start %d, end %d", g.Len(), g.Start, g.End)
+	}
+	if got := g.Neighbors(2); !reflect.DeepEqual(got, []int{1, 0}) {
+		t.Errorf("Neighbors(end) = %v, want [1 0]", got)
+	}
+	if id, ok := g.ID("a"); !ok || id != 1 {
+		t.Errorf("ID(a) = %d, %v", id, ok)
+	}
+	if id, ok := g.RoomAt(2, 0); !ok || id != 2 {
+		t.Errorf("RoomAt(2, 0) = %d, %v", id, ok)
+	}
+	if _, ok := g.RoomAt(5, 5); ok {
+		t.Errorf("RoomAt(5, 5) found a room")
+	}
+	if got := g.PathNames([]int{0, 1, 2}); !reflect.DeepEqual(got.RoomsInThePath, []string{"start", "a", "end"}) {
+		t.Errorf("PathNames() = %v", got)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	colony := &AntColony{
+		NumberOfAnts: 2,
+		Start:        "start",
+		End:          "end",
+		Rooms: []Room{
+			{Name: "start"}, {Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "end"},
+			{Name: "d"}, {Name: "e"}, {Name: "f"}, {Name: "g"}, {Name: "h"},
+		},
+		Links: map[string][]string{
+			"start": {"a", "c"},
+			"a":     {"start", "b"},
+			"b":     {"a", "c"},
+			"c":     {"b", "start", "end", "d"},
+			"end":   {"c"},
+			"d":     {"c", "e", "f"},
+			"e":     {"d"},
+			"f":     {"d"},
+			"g":     {"h"},
+			"h":     {"g"},
+		},
+	}
+	g := NewGraph(colony)
+	r := Reduce(g)
+
+	// The dead-end subtree d, e, f and the island g, h are pruned
+	if want := []bool{true, true, true, true, true, false, false, false, false, false}; !reflect.DeepEqual(r.Kept, want) {
+		t.Errorf("Kept = %v, want %v", r.Kept, want)
+	}
+	if want := []Chain{{From: 0, To: 3, Rooms: []int{1, 2}}}; !reflect.DeepEqual(r.Chains, want) {
+		t.Fatalf("Chains = %v, want %v", r.Chains, want)
+	}
+	if want := []Edge{{To: 3, Chain: 0}, {To: 3, Chain: -1}}; !reflect.DeepEqual(r.Edges[0], want) {
+		t.Errorf("Edges[start] = %v, want %v", r.Edges[0], want)
+	}
+	if got := r.Length(r.Edges[0][0]); got != 3 {
+		t.Errorf("Length(chain) = %d, want 3", got)
+	}
+	if got := r.Expand([]int{0}, 0, r.Edges[0][0]); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Errorf("Expand(from start) = %v, want [0 1 2]", got)
+	}
+	if got := r.Expand([]int{3}, 3, Edge{To: 0, Chain: 0}); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+		t.Errorf("Expand(from c) = %v, want [3 2 1]", got)
+	}
+	if rooms, tunnels := r.Size(); rooms != 3 || tunnels != 3 {
+		t.Errorf("Size() = %d rooms, %d tunnels, want 3, 3", rooms, tunnels)
+	}
+	if rooms, tunnels := Unreduced(g).Size(); rooms != 10 || tunnels != 9 {
+		t.Errorf("Unreduced Size() = %d rooms, %d tunnels, want 10, 9", rooms, tunnels)
+	}
+}
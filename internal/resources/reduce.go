@@ -0,0 +1,239 @@
+package resources
+
+// Edge leads from a room to another through a tunnel or, when Chain is not
+// -1, through a chain of rooms that each have two tunnels.
+type Edge struct {
+	To    int
+	Chain int // Index in Reduced.Chains, or -1 for a plain tunnel
+}
+
+// Chain is a run of rooms with two tunnels each, contracted into one edge
+// between the rooms at its ends.
+type Chain struct {
+	From, To int
+	Rooms    []int // The rooms of the chain in order from From to To
+}
+
+// Reduced is the part of a Graph the solvers need: rooms that cannot be on a
+// path from start to end are pruned and chains of rooms with two tunnels are
+// contracted into single edges whose length is the number of moves along them.
+// Room numbers are those of the Graph; pruned and contracted rooms have no edges.
+type Reduced struct {
+	*Graph
+	Kept   []bool   // Rooms left after pruning, chains included
+	Edges  [][]Edge // Edges of each room left after contraction
+	Chains []Chain
+}
+
+// Unreduced returns the graph as a Reduced with nothing pruned or contracted.
+func Unreduced(g *Graph) *Reduced {
+	r := &Reduced{Graph: g, Kept: make([]bool, g.Len()), Edges: make([][]Edge, g.Len())}
+	for room := range r.Edges {
+		r.Kept[room] = true
+		for _, next := range g.Neighbors(room) {
+			r.Edges[room] = append(r.Edges[room], Edge{To: next, Chain: -1})
+		}
+	}
+	return r
+}
+
+// Reduce prunes the rooms that no simple path from start to end can visit:
+// rooms not connected to the start room and dead-end subtrees, which are
+// removed one room with a single tunnel at a time. It then contracts the
+// chains of rooms with exactly two tunnels. Every simple path of the graph from
+// start to end has a counterpart in the reduced graph, of the same length.
+func Reduce(g *Graph) *Reduced {
+	r := &Reduced{Graph: g, Kept: Prune(g), Edges: make([][]Edge, g.Len())}
+
+	// Rooms with two tunnels to kept rooms are inside chains
+	neighbors := make([][]int, g.Len())
+	inChain := make([]bool, g.Len())
+	for room := range neighbors {
+		if !r.Kept[room] {
+			continue
+		}
+		neighbors[room] = r.keptNeighbors(room)
+		inChain[room] = room != g.Start && room != g.End && len(neighbors[room]) == 2
+	}
+
+	chainOf := make([]int, g.Len()) // Index in r.Chains of the chain holding each room
+	for room := range chainOf {
+		chainOf[room] = -1
+	}
+	for room := 0; room < g.Len(); room++ {
+		if !r.Kept[room] || inChain[room] {
+			continue
+		}
+		for _, next := range neighbors[room] {
+			if !inChain[next] {
+				r.Edges[room] = append(r.Edges[room], Edge{To: next, Chain: -1})
+				continue
+			}
+			if chainOf[next] < 0 {
+				r.walkChain(room, next, neighbors, inChain, chainOf)
+			}
+			chain := chainOf[next]
+			if chain < 0 {
+				continue // A loop back to the same room, which no simple path uses
+			}
+			to := r.Chains[chain].To
+			if to == room {
+				to = r.Chains[chain].From
+			}
+			r.Edges[room] = append(r.Edges[room], Edge{To: to, Chain: chain})
+		}
+	}
+	return r
+}
+
+// walkChain follows the chain entered from room through first and records it,
+// unless it leads back to room.
+func (r *Reduced) walkChain(room, first int, neighbors [][]int, inChain []bool, chainOf []int) {
+	rooms := []int{first}
+	prev, current := room, first
+	for inChain[current] {
+		next := neighbors[current][0]
+		if next == prev {
+			next = neighbors[current][1]
+		}
+		prev, current = current, next
+		if inChain[current] {
+			rooms = append(rooms, current)
+		}
+	}
+	if current == room {
+		for _, id := range rooms {
+			r.Kept[id] = false
+		}
+		return
+	}
+	r.Chains = append(r.Chains, Chain{From: room, To: current, Rooms: rooms})
+	for _, id := range rooms {
+		chainOf[id] = len(r.Chains) - 1
+	}
+}
+
+// keptNeighbors returns the distinct kept rooms linked to the room.
+func (r *Reduced) keptNeighbors(room int) []int {
+	var kept []int
+	for _, next := range r.Neighbors(room) {
+		if r.Kept[next] && next != room && !containsInt(kept, next) {
+			kept = append(kept, next)
+		}
+	}
+	return kept
+}
+
+// Connected reports which rooms are connected to the start room. The end room
+// is always included.
+func Connected(g *Graph) []bool {
+	kept := make([]bool, g.Len())
+	if g.Start < 0 {
+		return kept
+	}
+	kept[g.Start] = true
+	queue := []int{g.Start}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		for _, next := range g.Neighbors(room) {
+			if !kept[next] {
+				kept[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	if g.End >= 0 {
+		kept[g.End] = true
+	}
+	return kept
+}
+
+// Prune reports which rooms can be on a simple path from start to end: the
+// connected rooms less the dead-end subtrees.
+func Prune(g *Graph) []bool {
+	kept := Connected(g)
+
+	// Peel rooms with at most one tunnel left until none remain
+	var queue []int
+	degree := make([]int, g.Len())
+	for room := range degree {
+		if kept[room] {
+			degree[room] = len(distinctKept(g, kept, room))
+		}
+	}
+	for room := range degree {
+		if kept[room] && degree[room] <= 1 && room != g.Start && room != g.End {
+			queue = append(queue, room)
+		}
+	}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		if !kept[room] {
+			continue
+		}
+		kept[room] = false
+		for _, next := range distinctKept(g, kept, room) {
+			if degree[next]--; degree[next] <= 1 && next != g.Start && next != g.End {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return kept
+}
+
+// distinctKept returns the distinct kept rooms linked to the room.
+func distinctKept(g *Graph, kept []bool, room int) []int {
+	var rooms []int
+	for _, next := range g.Neighbors(room) {
+		if kept[next] && next != room && !containsInt(rooms, next) {
+			rooms = append(rooms, next)
+		}
+	}
+	return rooms
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Length returns the number of moves along an edge.
+func (r *Reduced) Length(e Edge) int {
+	if e.Chain < 0 {
+		return 1
+	}
+	return len(r.Chains[e.Chain].Rooms) + 1
+}
+
+// Expand appends the rooms inside the edge taken from room from to rooms, in travel order.
+func (r *Reduced) Expand(rooms []int, from int, e Edge) []int {
+	if e.Chain < 0 {
+		return rooms
+	}
+	chain := r.Chains[e.Chain]
+	if chain.From == from {
+		return append(rooms, chain.Rooms...)
+	}
+	for i := len(chain.Rooms) - 1; i >= 0; i-- {
+		rooms = append(rooms, chain.Rooms[i])
+	}
+	return rooms
+}
+
+// Size returns the rooms and tunnels left, a contracted chain counting as one
+// tunnel and none of its rooms.
+func (r *Reduced) Size() (rooms, tunnels int) {
+	for room, edges := range r.Edges {
+		if r.Kept[room] && (len(edges) > 0 || room == r.Start || room == r.End) {
+			rooms++
+		}
+		tunnels += len(edges)
+	}
+	return rooms, tunnels / 2
+}
@@ -0,0 +1,48 @@
+package utils
+
+import "lem-in/internal/resources"
+
+// MinVertexCut returns a smallest set of rooms whose removal disconnects the
+// end room from the start room, in declaration order. Their number is the most
+// room-disjoint paths the colony has, so they are the rooms limiting how many
+// ants can travel at once. It returns false when no set of rooms separates
+// start and end, either because a tunnel joins them or because one is missing.
+func MinVertexCut(colony *resources.AntColony) ([]string, bool) {
+	graph := resources.NewGraph(colony)
+	if graph.Start < 0 || graph.End < 0 {
+		return nil, false
+	}
+	for _, next := range graph.Neighbors(graph.Start) {
+		if next == graph.End {
+			return nil, false
+		}
+	}
+
+	// Tunnels get more capacity than any flow so that only rooms are cut
+	network, source, sink := splitNetwork(resources.Unreduced(graph), graph.Len())
+	for network.augment(source, sink) {
+	}
+
+	// The cut rooms are entered from the source side of the residual network but not left
+	reached := make([]bool, len(network.edges))
+	reached[source] = true
+	queue := []int{source}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, e := range network.edges[node] {
+			if e.cap > 0 && !reached[e.to] {
+				reached[e.to] = true
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	cut := []string{}
+	for room := 0; room < graph.Len(); room++ {
+		if room != graph.Start && room != graph.End && reached[2*room] && !reached[2*room+1] {
+			cut = append(cut, graph.Names[room])
+		}
+	}
+	return cut, true
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"lem-in/internal/resources"
+)
+
+// Annotation lines attach metadata to a room without changing the map for
+// other lem-in tools, which read them as comments:
+//
+//	#@ room=gate label="Main gate" tag=chokepoint color=#ff8800
+//
+// Values containing spaces, quotes or '=' are quoted, with \" and \\ as escapes.
+// The room may be defined before or after its annotations, and later values
+// replace earlier ones. The values end up in Room.Meta. Other #@ lines, such
+// as "#@ just a note", stay ordinary comments unless the map is parsed in
+// strict mode, which reports them.
+
+const annotationPrefix = "#@"
+
+// isAnnotation reports whether a line starts like a room annotation.
+func isAnnotation(line string) bool {
+	return strings.HasPrefix(line, annotationPrefix)
+}
+
+// isRoomAnnotation reports whether a line is an annotation that parses and
+// names its room.
+func isRoomAnnotation(line string) bool {
+	_, _, err := parseAnnotation(line)
+	return isAnnotation(line) && err == nil
+}
+
+// parseAnnotation returns the room and the key=value pairs of an annotation line.
+func parseAnnotation(line string) (string, map[string]string, error) {
+	fields, err := splitAnnotation(strings.TrimPrefix(line, annotationPrefix))
+	if err != nil {
+		return "", nil, err
+	}
+
+	room := ""
+	meta := make(map[string]string)
+	for _, field := range fields {
+		key, value := field[0], field[1]
+		if key == "room" {
+			room = value
+			continue
+		}
+		meta[key] = value
+	}
+	if room == "" {
+		return "", nil, errors.New("annotation without room=")
+	}
+	return room, meta, nil
+}
+
+// splitAnnotation splits the space separated key=value pairs of an annotation.
+func splitAnnotation(s string) ([][2]string, error) {
+	var fields [][2]string
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return fields, nil
+		}
+
+		eq := strings.IndexByte(s, '=')
+		space := strings.IndexByte(s, ' ')
+		if eq <= 0 || (space >= 0 && space < eq) {
+			return nil, fmt.Errorf("invalid annotation field: %s", strings.Fields(s)[0])
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var err error
+			value, s, err = readQuoted(s)
+			if err != nil {
+				return nil, err
+			}
+			if s != "" && s[0] != ' ' {
+				return nil, fmt.Errorf("invalid annotation value for %s", key)
+			}
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end == -1 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+		}
+		fields = append(fields, [2]string{key, value})
+	}
+}
+
+// readQuoted reads a quoted string with \" and \\ escapes from the start of s
+// and returns it unquoted along with the rest of s.
+func readQuoted(s string) (string, string, error) {
+	var value strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return value.String(), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) || (s[i+1] != '"' && s[i+1] != '\\') {
+				return "", "", fmt.Errorf("invalid escape in %s", s)
+			}
+			i++
+			value.WriteByte(s[i])
+		default:
+			value.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote in %s", s)
+}
+
+// applyAnnotations stores the annotations in the Meta of their rooms.
+func (p *parser) applyAnnotations(colony *resources.AntColony) bool {
+	index := make(map[string]int, len(colony.Rooms))
+	for i, room := range colony.Rooms {
+		index[room.Name] = i
+	}
+
+	for _, line := range p.annotations {
+		name, meta, err := parseAnnotation(line.text)
+		if err == nil {
+			if _, exists := index[name]; !exists {
+				err = fmt.Errorf("annotation for unknown room: %s", name)
+			}
+		}
+		if err != nil {
+			if p.report(line, err) {
+				return true
+			}
+			continue
+		}
+
+		room := &colony.Rooms[index[name]]
+		if room.Meta == nil {
+			room.Meta = make(map[string]string)
+		}
+		for key, value := range meta {
+			room.Meta[key] = value
+		}
+	}
+	return false
+}
+
+// FormatAnnotation writes the annotation line for a room's metadata, with keys sorted.
+func FormatAnnotation(room string, meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(annotationPrefix + " room=" + quoteValue(room))
+	for _, key := range keys {
+		b.WriteString(" " + key + "=" + quoteValue(meta[key]))
+	}
+	return b.String()
+}
+
+// quoteValue quotes an annotation value when it cannot be written bare.
+func quoteValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` "=\`) {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,123 @@
+package utils
+
+import "lem-in/internal/resources"
+
+// CurvePoint is what FindPaths chooses for one number of ants.
+type CurvePoint struct {
+	Ants  int
+	Turns int
+	Set   string           // The candidate set chosen: OptimizedPaths1, OptimizedPaths2 or disjoint
+	Paths []resources.Path // The paths of the set that carry ants
+}
+
+// Curve returns what FindPaths chooses for every number of ants from 1 to
+// maxAnts, or nil when the end room cannot be reached. The path search and the
+// augmenting paths only depend on the map, so they run once, and the ants are
+// added to each candidate set one at a time instead of being placed again for
+// every count.
+func Curve(colony *resources.AntColony, maxAnts int) []CurvePoint {
+	graph := resources.Unreduced(resources.NewGraph(colony))
+	if graph.Start < 0 || graph.End < 0 || maxAnts < 1 {
+		return nil
+	}
+	paths := searchPaths(graph, pathSearchBudget, tracer{})
+	flowSets := augmentedSets(graph, maxAnts)
+	if len(paths) == 0 && len(flowSets) == 0 {
+		return nil
+	}
+
+	var first *placement
+	longest := 0
+	if len(paths) > 0 {
+		first = newPlacement(optimizedPaths1(paths, tracer{}))
+		for _, path := range paths {
+			longest = max(longest, len(path)-1)
+		}
+	}
+	// OptimizedPaths2 only depends on the ants through the longest path it keeps
+	second := make(map[int]*placement)
+	disjoint := make([]*placement, len(flowSets))
+	for i, set := range flowSets {
+		disjoint[i] = newPlacement(set)
+	}
+
+	points := make([]CurvePoint, 0, maxAnts)
+	for ants := 1; ants <= maxAnts; ants++ {
+		var chosen *placement
+		set, turns := "", 0
+		if first != nil {
+			half := min(ants/2, longest)
+			if second[half] == nil {
+				withAnts := *colony
+				withAnts.NumberOfAnts = ants
+				second[half] = newPlacement(optimizedPaths2(paths, &withAnts, tracer{}))
+			}
+			chosen, set, turns = first, "OptimizedPaths1", first.turns(ants)
+			if turns2 := second[half].turns(ants); turns2 < turns {
+				chosen, set, turns = second[half], "OptimizedPaths2", turns2
+			}
+		}
+
+		// As in disjointPaths, stop at the first set needing more turns than the best so far
+		var best *placement
+		bestTurns := 0
+		for _, candidate := range disjoint[:min(ants, len(disjoint))] {
+			candidateTurns := candidate.turns(ants)
+			if best != nil && candidateTurns > bestTurns {
+				break
+			}
+			if best == nil || candidateTurns < bestTurns {
+				best, bestTurns = candidate, candidateTurns
+			}
+		}
+		if best != nil && (chosen == nil || bestTurns < turns) {
+			chosen, set, turns = best, "disjoint", bestTurns
+		}
+
+		points = append(points, CurvePoint{Ants: ants, Turns: turns, Set: set, Paths: namedPaths(graph.Graph, chosen.used())})
+	}
+	return points
+}
+
+// augmentedSets returns the path set after each augmenting path, as considered by disjointPaths.
+func augmentedSets(graph *resources.Reduced, maxAnts int) [][][]int {
+	network, source, sink := splitNetwork(graph, 1)
+	var sets [][][]int
+	for len(sets) < maxAnts && network.augment(source, sink) {
+		sets = append(sets, flowPaths(graph, network))
+	}
+	return sets
+}
+
+// placement is a path set with ants placed on it one at a time, so placing one
+// more ant continues where PlaceAnts stopped for one ant fewer.
+type placement struct {
+	paths      [][]int
+	lengths    []int
+	assignment map[int][]int
+	ants       int
+}
+
+func newPlacement(paths [][]int) *placement {
+	return &placement{paths: paths, lengths: pathLengths(paths), assignment: make(map[int][]int)}
+}
+
+// turns places ants up to the given number and returns the turns they need.
+func (p *placement) turns(ants int) int {
+	for p.ants < ants {
+		p.ants++
+		placeAnt(p.ants, p.lengths, p.assignment)
+	}
+	return countTurns(p.assignment, p.lengths)
+}
+
+// used returns the paths carrying ants.
+func (p *placement) used() [][]int {
+	var used [][]int
+	for i, path := range p.paths {
+		if len(p.assignment[i]) > 0 {
+			used = append(used, path)
+		}
+	}
+	return used
+}
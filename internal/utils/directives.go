@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"lem-in/internal/resources"
+)
+
+// DirectiveHandler handles a custom ##<name> command. args is the text after the
+// name, next is the line that follows the command ("" at the end of the input)
+// and colony holds everything parsed so far. A returned error is reported at the
+// command's line.
+type DirectiveHandler func(args, next string, colony *resources.AntColony) error
+
+// DirectiveRegistry holds the handlers for custom ## commands. The command and
+// its arguments are also attached to the next room defined, in Room.Directives.
+type DirectiveRegistry struct {
+	handlers map[string]DirectiveHandler
+}
+
+// NewDirectiveRegistry returns an empty registry.
+func NewDirectiveRegistry() *DirectiveRegistry {
+	return &DirectiveRegistry{handlers: make(map[string]DirectiveHandler)}
+}
+
+// Register adds the handler for ##<name>. The start and end commands are built
+// in and cannot be replaced.
+func (r *DirectiveRegistry) Register(name string, handler DirectiveHandler) error {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return fmt.Errorf("invalid directive name: %q", name)
+	}
+	if name == "start" || name == "end" {
+		return fmt.Errorf("directive ##%s is built in", name)
+	}
+	if _, exists := r.handlers[name]; exists {
+		return fmt.Errorf("directive ##%s is already registered", name)
+	}
+	r.handlers[name] = handler
+	return nil
+}
+
+// lookup returns the handler registered for name, if any. A nil registry has none.
+func (r *DirectiveRegistry) lookup(name string) (DirectiveHandler, bool) {
+	if r == nil {
+		return nil, false
+	}
+	handler, exists := r.handlers[name]
+	return handler, exists
+}
+
+// splitDirective splits a "##name args" line.
+func splitDirective(line string) (string, string) {
+	name, args, _ := strings.Cut(strings.TrimPrefix(line, "##"), " ")
+	return name, strings.TrimSpace(args)
+}
+
+// isBuiltinDirective reports whether a line is read as ##start or ##end, which
+// the parser has always matched by prefix.
+func isBuiltinDirective(line string) bool {
+	return strings.HasPrefix(line, "##start") || strings.HasPrefix(line, "##end")
+}
@@ -0,0 +1,407 @@
+package utils
+
+import (
+	"log/slog"
+	"sort"
+	"time"
+
+	"lem-in/internal/resources"
+)
+
+// pathSearchBudget bounds the work spent enumerating simple paths, which grows
+// exponentially with the number of rooms. Large maps fall back to disjointPaths.
+const pathSearchBudget = 1 << 22
+
+// pathState is a partial path in the search, stored as its last room, the
+// state it extends, the chain of rooms leading to its last room and its length.
+type pathState struct {
+	room, parent, chain int
+	length              int // Rooms in the path
+	depth               int // States in the path, fewer than rooms when chains were crossed
+}
+
+// FindPaths finds all possible paths from start to end using BFS.
+func FindPaths(colony *resources.AntColony) ([]resources.Path, map[int][]int, int) {
+	return FindPathsWith(colony, SolveOptions{})
+}
+
+// FindPathsWith is FindPaths with tracing and statistics. opts.Trace receives
+// every decision of the solver at debug level: the paths found by the search,
+// each candidate set with its ant placement and turns, the paths discarded or
+// replaced while building the sets and the set finally chosen.
+func FindPathsWith(colony *resources.AntColony, opts SolveOptions) ([]resources.Path, map[int][]int, int) {
+	stats := opts.Stats
+	if stats == nil {
+		stats = &Stats{}
+	}
+	t := tracer{log: opts.Trace, stats: stats, ctx: opts.Context}
+	start := time.Now()
+	graph := reduce(resources.NewGraph(colony), opts, t)
+	t.graph = graph.Graph
+	stats.Reduce += time.Since(start)
+	if graph.Start < 0 || graph.End < 0 {
+		t.event("no start or end room")
+		return nil, map[int][]int{}, 0
+	}
+
+	start = time.Now()
+	paths := searchPaths(graph, pathSearchBudget, t)
+	stats.Search += time.Since(start)
+
+	if t.stopped() {
+		return nil, map[int][]int{}, 0
+	}
+
+	start = time.Now()
+	best, assignment, turns := chooseOptimumPath(paths, colony, t)
+	stats.Assign += time.Since(start)
+
+	// Augmenting paths find good disjoint sets even when the search ran out of budget
+	start = time.Now()
+	disjoint := disjointPaths(graph, colony, t)
+	stats.Search += time.Since(start)
+	if t.stopped() {
+		return nil, map[int][]int{}, 0
+	}
+	if len(disjoint) > 0 {
+		start = time.Now()
+		disjointAssignment, disjointTurns := t.placeAndCount("disjoint", colony, disjoint)
+		stats.Assign += time.Since(start)
+		if len(best) == 0 || disjointTurns < turns {
+			t.event("final", slog.String("set", "disjoint"), slog.Int("turns", disjointTurns), slog.Int("searchTurns", turns))
+			return namedPaths(graph.Graph, disjoint), disjointAssignment, disjointTurns
+		}
+		t.event("final", slog.String("set", "search"), slog.Int("turns", turns), slog.Int("disjointTurns", disjointTurns))
+	}
+	return namedPaths(graph.Graph, best), assignment, turns
+}
+
+// namedPaths converts paths of room IDs to paths of room names, once solving is done.
+func namedPaths(graph *resources.Graph, paths [][]int) []resources.Path {
+	named := make([]resources.Path, len(paths))
+	for i, path := range paths {
+		named[i] = graph.PathNames(path)
+	}
+	return named
+}
+
+// searchPaths lists simple paths from start to end shortest first, until the
+// budget of visited rooms is spent. Partial paths are expanded in order of
+// length, and in the order they were found among paths of the same length, so
+// without contracted chains this is a breadth-first search. Paths are returned
+// as room IDs.
+func searchPaths(graph *resources.Reduced, budget int, t tracer) [][]int {
+	paths := [][]int{}
+	states := []pathState{{room: graph.Start, parent: -1, chain: -1, length: 1, depth: 1}}
+	buckets := [][]int{nil, {0}} // Partial paths waiting to be expanded, by length
+	waiting, queuePeak := 1, 0
+	defer func() {
+		t.event("search", slog.Int("paths", len(paths)), slog.Int("states", len(states)), slog.Bool("budgetSpent", budget < 0))
+		if t.stats != nil {
+			t.stats.Paths += len(paths)
+			t.stats.QueuePeak = max(t.stats.QueuePeak, queuePeak)
+		}
+	}()
+
+	for length := 1; length < len(buckets); length++ {
+		for _, current := range buckets[length] {
+			state := states[current]
+			queuePeak = max(queuePeak, waiting)
+			waiting--
+
+			// If we've reached the end, add the path to allPaths
+			if state.room == graph.End {
+				paths = append(paths, statePath(graph, states, current))
+				continue
+			}
+
+			// Give up when the caller no longer wants the result
+			if current%1024 == 0 && t.stopped() {
+				return paths
+			}
+
+			// Explore adjacent rooms
+			for _, e := range graph.Edges[state.room] {
+				budget -= state.depth
+				if budget < 0 {
+					return paths
+				}
+				if stateContains(states, current, e.To) {
+					continue
+				}
+				next := pathState{room: e.To, parent: current, chain: e.Chain, length: state.length + graph.Length(e), depth: state.depth + 1}
+				for len(buckets) <= next.length {
+					buckets = append(buckets, nil)
+				}
+				buckets[next.length] = append(buckets[next.length], len(states))
+				states = append(states, next)
+				waiting++
+			}
+		}
+		buckets[length] = nil
+	}
+	return paths
+}
+
+// statePath returns the rooms of the partial path ending in the given state,
+// with the rooms of the chains it crosses.
+func statePath(graph *resources.Reduced, states []pathState, index int) []int {
+	steps := make([]int, states[index].depth)
+	for i := len(steps) - 1; i >= 0; i-- {
+		steps[i] = index
+		index = states[index].parent
+	}
+	rooms := make([]int, 0, states[steps[len(steps)-1]].length)
+	for i, step := range steps {
+		if i > 0 {
+			rooms = graph.Expand(rooms, states[steps[i-1]].room, resources.Edge{To: states[step].room, Chain: states[step].chain})
+		}
+		rooms = append(rooms, states[step].room)
+	}
+	return rooms
+}
+
+// stateContains reports whether the partial path ending in the given state
+// visits room. Rooms inside chains need no check: a chain can only be entered
+// again through one of its ends.
+func stateContains(states []pathState, index, room int) bool {
+	for ; index >= 0; index = states[index].parent {
+		if states[index].room == room {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to check if a room is in the path
+func containsRoom(path []string, room string) bool {
+	for _, r := range path {
+		if r == room {
+			return true
+		}
+	}
+	return false
+}
+
+// disjointPaths finds room-disjoint path sets with augmenting paths on a network
+// where every room other than start and end has capacity 1. It adds one path at a
+// time and returns the set that needs the fewest turns for the colony's ants.
+func disjointPaths(graph *resources.Reduced, colony *resources.AntColony, t tracer) [][]int {
+	network, source, sink := splitNetwork(graph, 1)
+
+	var best [][]int
+	bestTurns := 0
+	for k := 0; k < colony.NumberOfAnts && !t.stopped() && network.augment(source, sink); k++ {
+		paths := flowPaths(graph, network)
+		lengths := pathLengths(paths)
+		turns := countTurns(placeAnts(colony.NumberOfAnts, lengths), lengths)
+		t.event("augment", slog.Int("paths", len(paths)), slog.Int("turns", turns))
+		if t.stats != nil {
+			t.stats.Augmentations++
+		}
+		if best != nil && turns > bestTurns {
+			break // Longer detours only add turns from here on
+		}
+		if best == nil || turns < bestTurns {
+			best, bestTurns = paths, turns
+		}
+	}
+	return best
+}
+
+// splitNetwork builds the flow network of the graph where every room other than
+// start and end has capacity 1 and every tunnel the given capacity, and returns
+// it with its source and sink nodes. A contracted chain is crossed through its
+// first room, weighted so that augment counts the same length as through the
+// whole chain.
+func splitNetwork(graph *resources.Reduced, tunnelCapacity int) (*flowNetwork, int, int) {
+	// Room i is split into node 2i for entering and 2i+1 for leaving it
+	network := &flowNetwork{edges: make([][]flowEdge, 2*graph.Len())}
+	weights := make([]int, graph.Len())
+	for _, chain := range graph.Chains {
+		weights[chain.Rooms[0]] = 2*len(chain.Rooms) - 1
+	}
+	for room := 0; room < graph.Len(); room++ {
+		network.addWeightedEdge(2*room, 2*room+1, 1, max(weights[room], 1))
+		for _, e := range graph.Edges[room] {
+			if e.To == graph.Start || room == graph.End {
+				continue
+			}
+			if e.Chain < 0 {
+				network.addEdge(2*room+1, 2*e.To, tunnelCapacity)
+				continue
+			}
+			first := graph.Chains[e.Chain].Rooms[0]
+			network.addEdge(2*room+1, 2*first, tunnelCapacity)
+			network.addEdge(2*first+1, 2*e.To, tunnelCapacity)
+		}
+	}
+	return network, 2*graph.Start + 1, 2 * graph.End
+}
+
+// flowPaths decomposes the flow of the split network into paths of room IDs, shortest first.
+func flowPaths(graph *resources.Reduced, network *flowNetwork) [][]int {
+	chainAt := make(map[int]int, len(graph.Chains)) // Chains by their first room
+	for i, chain := range graph.Chains {
+		chainAt[chain.Rooms[0]] = i
+	}
+
+	var paths [][]int
+	for _, first := range network.edges[2*graph.Start+1] {
+		if !first.forward || first.cap > 0 {
+			continue
+		}
+		rooms := []int{graph.Start}
+		for node := first.to; ; {
+			room := node / 2
+			if chain, ok := chainAt[room]; ok {
+				rooms = graph.Expand(rooms, rooms[len(rooms)-1], resources.Edge{Chain: chain})
+			} else {
+				rooms = append(rooms, room)
+			}
+			if room == graph.End {
+				break
+			}
+			for _, e := range network.edges[2*room+1] {
+				if e.forward && e.cap == 0 {
+					node = e.to
+					break
+				}
+			}
+		}
+		paths = append(paths, rooms)
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		return len(paths[i]) < len(paths[j])
+	})
+	return paths
+}
+
+// reduce returns the graph the solvers work on, pruned and with its chains
+// contracted when opts.Reduce is set, and records how much it shrank.
+func reduce(graph *resources.Graph, opts SolveOptions, t tracer) *resources.Reduced {
+	reduced := resources.Unreduced(graph)
+	if opts.Reduce {
+		reduced = resources.Reduce(graph)
+	}
+	rooms, tunnels := graph.Len(), len(graph.Adjacency)/2
+	reducedRooms, reducedTunnels := reduced.Size()
+	t.event("reduce", slog.Int("rooms", rooms), slog.Int("tunnels", tunnels), slog.Int("reducedRooms", reducedRooms), slog.Int("reducedTunnels", reducedTunnels), slog.Int("chains", len(reduced.Chains)))
+	if t.stats != nil {
+		t.stats.Rooms, t.stats.Tunnels = rooms, tunnels
+		t.stats.ReducedRooms, t.stats.ReducedTunnels = reducedRooms, reducedTunnels
+	}
+	return reduced
+}
+
+// ChooseOptimumPath selects the optimum paths of room IDs based on the number of turns.
+// It returns no paths when the end room cannot be reached from the start room.
+func ChooseOptimumPath(paths [][]int, colony *resources.AntColony) ([][]int, map[int][]int, int) {
+	return chooseOptimumPath(paths, colony, tracer{})
+}
+
+func chooseOptimumPath(paths [][]int, colony *resources.AntColony, t tracer) ([][]int, map[int][]int, int) {
+	if len(paths) == 0 {
+		t.event("no path")
+		return nil, map[int][]int{}, 0
+	}
+	shortest1 := optimizedPaths1(paths, t)
+	shortest2 := optimizedPaths2(paths, colony, t)
+	firstop, turns1 := t.placeAndCount("OptimizedPaths1", colony, shortest1)
+	secondop, turns2 := t.placeAndCount("OptimizedPaths2", colony, shortest2)
+
+	// Choose the path with fewer turns
+	if turns1 <= turns2 {
+		t.event("choose", slog.String("set", "OptimizedPaths1"), slog.Int("turns", turns1), slog.Int("otherTurns", turns2))
+		return shortest1, firstop, turns1
+	}
+	t.event("choose", slog.String("set", "OptimizedPaths2"), slog.Int("turns", turns2), slog.Int("otherTurns", turns1))
+	return shortest2, secondop, turns2
+}
+
+// OptimizedPaths1 filters paths of room IDs that don't share rooms.
+func OptimizedPaths1(paths [][]int) [][]int {
+	return optimizedPaths1(paths, tracer{})
+}
+
+func optimizedPaths1(paths [][]int, t tracer) [][]int {
+	optimized := [][]int{paths[0]}
+	for i := 1; i < len(paths); i++ {
+		if Check(paths[i], optimized) {
+			optimized = append(optimized, paths[i])
+		} else if t.enabled() {
+			t.event("discard", slog.String("set", "OptimizedPaths1"), slog.Any("path", t.names(paths[i])), slog.String("reason", "shares rooms"))
+		}
+	}
+	return optimized
+}
+
+// Check verifies if a path shares any rooms with already optimized paths (excluding start/end).
+func Check(path []int, optimized [][]int) bool {
+	// Use a map for faster lookups
+	visitedRooms := make(map[int]struct{})
+	for _, optpath := range optimized {
+		for _, room := range optpath[1 : len(optpath)-1] { // Ignore start and end rooms
+			visitedRooms[room] = struct{}{}
+		}
+	}
+
+	for _, room := range path[1 : len(path)-1] { // Ignore start and end rooms
+		if _, found := visitedRooms[room]; found {
+			return false
+		}
+	}
+	return true
+}
+
+// OptimizedPaths2 filters paths of room IDs based on colony's ant count and unique room usage.
+func OptimizedPaths2(paths [][]int, colony *resources.AntColony) [][]int {
+	return optimizedPaths2(paths, colony, tracer{})
+}
+
+func optimizedPaths2(paths [][]int, colony *resources.AntColony, t tracer) [][]int {
+	half := colony.NumberOfAnts / 2
+	optimized := [][]int{paths[0]}
+
+	for i := 1; i < len(paths); i++ {
+		if len(paths[i])-1 <= half { // Exclude start and end rooms
+			unique, index := Check2(paths[i], optimized)
+			if !unique {
+				// Replace path if lengths differ, unless the new one also shares rooms with another path
+				others := append(append([][]int{}, optimized[:index]...), optimized[index+1:]...)
+				if len(optimized[index]) != len(paths[i]) && Check(paths[i], others) {
+					if t.enabled() {
+						t.event("replace", slog.Int("index", index), slog.Any("old", t.names(optimized[index])), slog.Any("new", t.names(paths[i])))
+					}
+					optimized[index] = paths[i] // Efficient path replacement
+				} else if t.enabled() {
+					t.event("discard", slog.String("set", "OptimizedPaths2"), slog.Any("path", t.names(paths[i])), slog.String("reason", "shares rooms with a path of the same length or with several paths"), slog.Int("index", index))
+				}
+			} else {
+				optimized = append(optimized, paths[i])
+			}
+		} else if t.enabled() {
+			t.event("discard", slog.String("set", "OptimizedPaths2"), slog.Any("path", t.names(paths[i])), slog.String("reason", "longer than half the ants"))
+		}
+	}
+	return optimized
+}
+
+// Check2 checks if a path is unique and does not share rooms with existing paths.
+func Check2(path []int, optimized [][]int) (bool, int) {
+	for i, optpath := range optimized {
+		for _, room := range path[1 : len(path)-1] { // Exclude start and end rooms
+			for _, optRoom := range optpath[1 : len(optpath)-1] {
+				if room == optRoom {
+					return false, i
+				}
+			}
+		}
+	}
+	return true, 0
+}
+
+// Remove removes a path from the optimized slice by index.
+func Remove(optimized [][]int, index int) [][]int {
+	return append(optimized[:index], optimized[index+1:]...)
+}
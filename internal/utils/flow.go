@@ -0,0 +1,76 @@
+package utils
+
+// flowEdge is a residual edge in a flow network.
+type flowEdge struct {
+	to, cap, rev int
+	weight       int  // Length counted by augment, the same for the edge and its reverse
+	forward      bool // False for the reverse edges added by addEdge
+}
+
+// flowNetwork is a unit capacity flow network used by the path solvers.
+type flowNetwork struct {
+	edges [][]flowEdge
+}
+
+func (n *flowNetwork) addNode() int {
+	n.edges = append(n.edges, nil)
+	return len(n.edges) - 1
+}
+
+func (n *flowNetwork) addEdge(from, to, capacity int) {
+	n.addWeightedEdge(from, to, capacity, 1)
+}
+
+// addWeightedEdge adds an edge that augment counts as weight edges, such as
+// one standing for a contracted chain of rooms.
+func (n *flowNetwork) addWeightedEdge(from, to, capacity, weight int) {
+	n.edges[from] = append(n.edges[from], flowEdge{to: to, cap: capacity, rev: len(n.edges[to]), weight: weight, forward: true})
+	n.edges[to] = append(n.edges[to], flowEdge{to: from, cap: 0, rev: len(n.edges[from]) - 1, weight: weight})
+}
+
+// augment pushes one unit of flow along a shortest augmenting path from source
+// to sink and reports whether one was found. Lengths are sums of edge weights;
+// nodes at the same distance are expanded in the order they were reached, so
+// with unit weights this is a breadth-first search.
+func (n *flowNetwork) augment(source, sink int) bool {
+	type step struct{ node, edge int }
+	prev := make([]step, len(n.edges))
+	dist := make([]int, len(n.edges))
+	for i := range prev {
+		prev[i].node = -1
+		dist[i] = -1
+	}
+	prev[source].node = source
+	dist[source] = 0
+
+	buckets := [][]int{{source}} // Nodes by distance
+	for d := 0; d < len(buckets) && (dist[sink] < 0 || d < dist[sink]); d++ {
+		for _, node := range buckets[d] {
+			if dist[node] != d {
+				continue // Reached again by a shorter path
+			}
+			for i, e := range n.edges[node] {
+				next := d + e.weight
+				if e.cap > 0 && (dist[e.to] < 0 || next < dist[e.to]) {
+					dist[e.to] = next
+					prev[e.to] = step{node, i}
+					for len(buckets) <= next {
+						buckets = append(buckets, nil)
+					}
+					buckets[next] = append(buckets[next], e.to)
+				}
+			}
+		}
+		buckets[d] = nil
+	}
+	if prev[sink].node == -1 {
+		return false
+	}
+
+	for node := sink; node != source; node = prev[node].node {
+		e := &n.edges[prev[node].node][prev[node].edge]
+		e.cap--
+		n.edges[e.to][e.rev].cap++
+	}
+	return true
+}
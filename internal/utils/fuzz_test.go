@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lem-in/internal/resources"
+)
+
+// addColonySeeds seeds a fuzz target with the example maps and the maps from the unit tests.
+func addColonySeeds(f *testing.F) {
+	example, err := os.ReadFile(filepath.Join("..", "..", "cmd", "example00.txt"))
+	if err != nil {
+		f.Fatalf("failed to read seed map: %v", err)
+	}
+	f.Add(string(example))
+	f.Add("3\n##start\n1 23 3\n#comment to ignore\n2 16 7\n##end\n0 9 5\n1-2\n2-0\n")
+	f.Add("4\n##start\nstart 0 0\nroom1 1 0\nroom2 2 0\nroom3 3 0\n##end\nend 4 0\nstart-room1\nstart-room2\nroom1-room3\nroom2-end\nroom3-end\n")
+	f.Add("2\n##start\nstart 0 0\nA 1 0\nB 2 0\n##end\nend 3 0\nstart-A\nstart-B\nA-B\nB-end\n")
+	f.Add("5\n##start\nstart 0 0\n##end\nend 1 1\nstart-end\n")
+	f.Add("1\n##start\nstart 0 0\n##end\nend 1 1\n")
+	f.Add("")
+}
+
+// parseString parses a colony from an in-memory map.
+func parseString(t *testing.T, data string) (*resources.AntColony, error) {
+	filename := filepath.Join(t.TempDir(), "colony.txt")
+	if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write map: %v", err)
+	}
+	return ParseFile(filename)
+}
+
+func FuzzParseFile(f *testing.F) {
+	addColonySeeds(f)
+	f.Fuzz(func(t *testing.T, data string) {
+		colony, err := parseString(t, data)
+		if err != nil {
+			return
+		}
+		if colony.Start == "" || colony.End == "" {
+			t.Fatalf("colony parsed without start or end room: %+v", colony)
+		}
+		if colony.NumberOfAnts <= 0 {
+			t.Fatalf("colony parsed with %d ants", colony.NumberOfAnts)
+		}
+		for room, links := range colony.Links {
+			for _, next := range links {
+				if !containsRoom(colony.Links[next], room) {
+					t.Fatalf("link %s-%s is not bidirectional", room, next)
+				}
+			}
+		}
+	})
+}
+
+func FuzzParseRoom(f *testing.F) {
+	for _, line := range []string{"room1 23 45", "room1 23", "room1 23 45 67", "room1 abc 45", "L1 1 2", "#room 1 2", "room one 1 2", "room2 24 46"} {
+		f.Add(line)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		colony := &resources.AntColony{
+			Rooms: []resources.Room{{Name: "room1", Coord_X: 23, Coord_Y: 45}},
+		}
+		name, err := parseRoom(line, colony)
+		if err != nil {
+			return
+		}
+		if name == "" || name != strings.Fields(line)[0] {
+			t.Fatalf("parseRoom(%q) = %q", line, name)
+		}
+		if len(colony.Rooms) != 2 || colony.Rooms[1].Name != name {
+			t.Fatalf("parseRoom(%q) did not add the room", line)
+		}
+	})
+}
+
+func FuzzParseConnection(f *testing.F) {
+	for _, line := range []string{"room1-room2", "room1room2", "room1-room2-room3", "room1-room1", "nonexistent-room2", "room2-", "-"} {
+		f.Add(line)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		colony := &resources.AntColony{
+			Links: map[string][]string{"room1": {}, "room2": {}, "room3": {}},
+		}
+		if err := parseConnection(line, colony); err != nil {
+			return
+		}
+		links := 0
+		for room, next := range colony.Links {
+			links += len(next)
+			for _, other := range next {
+				if other == room || !containsRoom(colony.Links[other], room) {
+					t.Fatalf("parseConnection(%q) added invalid link %s-%s", line, room, other)
+				}
+			}
+		}
+		if links != 2 {
+			t.Fatalf("parseConnection(%q) added %d link ends, want 2", line, links)
+		}
+	})
+}
+
+func FuzzSolve(f *testing.F) {
+	addColonySeeds(f)
+	f.Fuzz(func(t *testing.T, data string) {
+		colony, err := parseString(t, data)
+		if err != nil {
+			return
+		}
+		// Path enumeration is exponential, so keep the maps small
+		if len(colony.Rooms) > 10 || colony.NumberOfAnts > 50 {
+			return
+		}
+
+		paths, antsPerPath, turns := FindPaths(colony)
+		if len(paths) == 0 {
+			graph := resources.NewGraph(colony)
+			if reachable(graph, graph.Start, graph.End) {
+				t.Fatalf("FindPaths() found no path but the end room is reachable")
+			}
+			return
+		}
+
+		moves := MoveAnts(paths, antsPerPath, turns)
+		if err := VerifyMoves(colony, moves); err != nil {
+			t.Fatalf("invalid moves %q for paths %v: %v", moves, paths, err)
+		}
+
+		optimum, err := SolveTimeExpanded(colony)
+		if err != nil {
+			t.Fatalf("SolveTimeExpanded() error = %v", err)
+		}
+		if optimum > turns {
+			t.Fatalf("SolveTimeExpanded() = %d, more than the heuristic's %d turns", optimum, turns)
+		}
+	})
+}
@@ -1,15 +1,20 @@
 package utils
 
-import "lem-in/resources"
+import "lem-in/internal/resources"
 
 // GenerateTurns calculates the maximum number of turns any path can have in the given options.
 func GenerateTurns(option map[int][]int, paths []resources.Path) int {
+	return countTurns(option, namedLengths(paths))
+}
+
+// countTurns is GenerateTurns on the number of rooms of each path.
+func countTurns(option map[int][]int, lengths []int) int {
 	maxTurns := 0
 
 	// Iterate through each path and calculate the number of turns
-	for i, path := range paths {
+	for i, length := range lengths {
 		// The number of rooms excluding the start room
-		rooms := len(path.RoomsInThePath) - 1
+		rooms := length - 1
 		// The number of ants for the current path
 		ants := len(option[i])
 		// Calculate turns: rooms + ants - 1
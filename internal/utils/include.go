@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// An ##include line splices another file into the map:
+//
+//	##include sections/ring.map prefix=north_ offset=20,0
+//
+// A relative path is resolved from the including file. An included file holds
+// rooms, links, commands and annotations, but no ant count. Its room names get
+// the prefix, in its own links and annotations too, and its coordinates are
+// shifted by the offset, so a section can be used several times and stitched
+// to the rest of the map by links naming the prefixed rooms. Includes may be
+// nested, in which case prefixes and offsets add up. The echoed map holds the
+// expanded lines, so the output stays a plain lem-in map.
+
+const includeDirective = "##include"
+
+// isInclude reports whether a line is an ##include command.
+func isInclude(line string) bool {
+	return line == includeDirective || strings.HasPrefix(line, includeDirective+" ")
+}
+
+// includeScope is a file being read and the renaming applied to its lines.
+type includeScope struct {
+	file   string // The name used in errors, "" for the main input
+	path   string // The absolute path, "" when unknown
+	dir    string // The directory nested includes are resolved against
+	prefix string
+	dx, dy int
+	line   int // The main input's ##include line the file comes from, 0 for the main input
+	parent *includeScope
+}
+
+// mainScope returns the scope of the main input, read from the named file if known.
+func mainScope(filename string) *includeScope {
+	if filename == "" {
+		return &includeScope{dir: "."}
+	}
+	path, _ := filepath.Abs(filename)
+	return &includeScope{path: path, dir: filepath.Dir(filename)}
+}
+
+// renames reports whether lines read in the scope are rewritten.
+func (s *includeScope) renames() bool {
+	return s.prefix != "" || s.dx != 0 || s.dy != 0
+}
+
+// include parses the arguments of an ##include line and returns the scope of the included file.
+func (s *includeScope) include(args string) (*includeScope, error) {
+	name, rest, err := readIncludePath(args)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := splitAnnotation(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative paths are resolved from the directory of the including file
+	file := name
+	if !filepath.IsAbs(name) {
+		file = filepath.Join(s.dir, name)
+	}
+	child := &includeScope{
+		file:   file,
+		prefix: s.prefix,
+		dx:     s.dx,
+		dy:     s.dy,
+		line:   s.line,
+		parent: s,
+	}
+	for _, field := range fields {
+		key, value := field[0], field[1]
+		switch key {
+		case "prefix":
+			child.prefix += value
+		case "offset":
+			dx, dy, err := parseOffset(value)
+			if err != nil {
+				return nil, err
+			}
+			child.dx += dx
+			child.dy += dy
+		default:
+			return nil, fmt.Errorf("unknown ##include option: %s", key)
+		}
+	}
+
+	child.dir = filepath.Dir(child.file)
+	if child.path, err = filepath.Abs(child.file); err != nil {
+		return nil, err
+	}
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.path == child.path {
+			return nil, fmt.Errorf("include cycle: %s", child.chain())
+		}
+	}
+	return child, nil
+}
+
+// chain lists the files from the main input down to s.
+func (s *includeScope) chain() string {
+	var files []string
+	for scope := s; scope != nil; scope = scope.parent {
+		name := scope.file
+		if scope.parent == nil && scope.path != "" {
+			name = filepath.Base(scope.path)
+		}
+		files = append([]string{name}, files...)
+	}
+	return strings.Join(files, " -> ")
+}
+
+// readIncludePath reads the bare or quoted path at the start of an ##include's arguments.
+func readIncludePath(args string) (string, string, error) {
+	if strings.HasPrefix(args, `"`) {
+		name, rest, err := readQuoted(args)
+		if err == nil && name == "" {
+			err = errors.New("##include without a file")
+		}
+		return name, rest, err
+	}
+	name, rest, _ := strings.Cut(args, " ")
+	if name == "" {
+		return "", "", errors.New("##include without a file")
+	}
+	return name, rest, nil
+}
+
+// parseOffset parses an offset=<dx>,<dy> value.
+func parseOffset(value string) (int, int, error) {
+	xs, ys, found := strings.Cut(value, ",")
+	dx, errX := strconv.Atoi(xs)
+	dy, errY := strconv.Atoi(ys)
+	if !found || errX != nil || errY != nil {
+		return 0, 0, fmt.Errorf("invalid ##include offset: %s", value)
+	}
+	return dx, dy, nil
+}
+
+// readInclude reads the lines of an included file.
+func (p *parser) readInclude(scope *includeScope) ([]sourceLine, error) {
+	file, err := os.Open(scope.file)
+	if err != nil {
+		return nil, fmt.Errorf("##include: %v", err)
+	}
+	defer file.Close()
+	return p.readSource(file, scope)
+}
+
+// renameRoom applies the scope's prefix and offset to a room line. Coordinates
+// that are not numbers are left for parseRoom to report.
+func (s *includeScope) renameRoom(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) != 3 {
+		return text
+	}
+	fields[0] = s.prefix + fields[0]
+	if x, err := strconv.Atoi(fields[1]); err == nil {
+		fields[1] = strconv.Itoa(x + s.dx)
+	}
+	if y, err := strconv.Atoi(fields[2]); err == nil {
+		fields[2] = strconv.Itoa(y + s.dy)
+	}
+	return strings.Join(fields, " ")
+}
+
+// renameAnnotation applies the scope's prefix to the room of an annotation.
+// Invalid annotations are left for applyAnnotations to report.
+func (s *includeScope) renameAnnotation(text string) string {
+	room, meta, err := parseAnnotation(text)
+	if err != nil {
+		return text
+	}
+	return FormatAnnotation(s.prefix+room, meta)
+}
+
+// renameLink applies the scope's prefix to both rooms of a link. rooms holds
+// the prefixed names of the rooms read so far, for links with several hyphens.
+func (s *includeScope) renameLink(text string, rooms map[string]bool) (string, error) {
+	from, to, err := splitLinkFunc(text, func(name string) bool {
+		return rooms[s.prefix+name]
+	})
+	if err != nil {
+		return "", err
+	}
+	return FormatLink(s.prefix+from, s.prefix+to), nil
+}
+
+// isLinkLine reports whether a kept line is read as a link, as Parse does.
+func isLinkLine(text string) bool {
+	return !strings.Contains(text, " ") && strings.Contains(text, "-") && !strings.HasPrefix(text, "##")
+}
+
+// isRoomLine reports whether a kept line is read as a room, as Parse does.
+func isRoomLine(text string) bool {
+	return strings.Contains(text, " ") && !strings.HasPrefix(text, "##")
+}
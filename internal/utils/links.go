@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"lem-in/internal/resources"
+)
+
+// Link lines have the form <room>-<room>. A room name is written either bare or
+// quoted:
+//
+//	bare    any characters except '-' and '"', e.g. gate1
+//	quoted  "..." where \" stands for a quote and \\ for a backslash,
+//	        e.g. "north-gate" or "say \"hi\""
+//
+// For compatibility with maps written before quoting existed, a bare link with
+// several hyphens such as north-gate-hall is accepted when exactly one way of
+// splitting it names two declared rooms.
+
+// splitLink returns the two room names of a link line.
+func splitLink(line string, colony *resources.AntColony) (string, string, error) {
+	return splitLinkFunc(line, func(name string) bool {
+		_, exists := colony.Links[name]
+		return exists
+	})
+}
+
+// splitLinkFunc is splitLink with exists reporting which rooms are declared.
+func splitLinkFunc(line string, exists func(name string) bool) (string, string, error) {
+	if !strings.Contains(line, `"`) {
+		return splitBareLink(line, exists)
+	}
+
+	from, rest, err := readLinkName(line)
+	if err != nil {
+		return "", "", err
+	}
+	rest, found := strings.CutPrefix(rest, "-")
+	if !found {
+		return "", "", fmt.Errorf("invalid room connection: %s", line)
+	}
+	to, rest, err := readLinkName(rest)
+	if err != nil {
+		return "", "", err
+	}
+	if rest != "" {
+		return "", "", fmt.Errorf("invalid room connection: %s", line)
+	}
+	return from, to, nil
+}
+
+// SplitLinkLine returns the two room names of a link line, with exists
+// reporting which rooms are declared.
+func SplitLinkLine(line string, exists func(name string) bool) (string, string, error) {
+	return splitLinkFunc(line, exists)
+}
+
+// splitBareLink splits a link line without quotes at the hyphen separating two declared rooms.
+func splitBareLink(line string, exists func(name string) bool) (string, string, error) {
+	parts := strings.Split(line, "-")
+	if len(parts) == 2 {
+		if parts[0] == "" || parts[1] == "" {
+			return "", "", errors.New("invalid room connection")
+		}
+		return parts[0], parts[1], nil
+	}
+	if len(parts) < 2 {
+		return "", "", errors.New("invalid room connection")
+	}
+
+	var from, to string
+	matches := 0
+	for i := 1; i < len(parts); i++ {
+		a := strings.Join(parts[:i], "-")
+		b := strings.Join(parts[i:], "-")
+		if exists(a) && exists(b) {
+			from, to = a, b
+			matches++
+		}
+	}
+
+	switch matches {
+	case 0:
+		return "", "", fmt.Errorf("invalid room connection: %s", line)
+	case 1:
+		return from, to, nil
+	default:
+		return "", "", fmt.Errorf("ambiguous room connection, quote the room names: %s", line)
+	}
+}
+
+// readLinkName reads one bare or quoted room name from the start of s and returns the remainder.
+func readLinkName(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s, `-"`)
+		if end == -1 {
+			end = len(s)
+		}
+		if end == 0 {
+			return "", "", errors.New("empty room name")
+		}
+		return s[:end], s[end:], nil
+	}
+
+	name, rest, err := readQuoted(s)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid quoted room name: %v", err)
+	}
+	if name == "" {
+		return "", "", errors.New("empty room name")
+	}
+	return name, rest, nil
+}
+
+// FormatLink writes a link between two rooms so that splitLink reads back the same names.
+// Names that cannot be written bare are quoted.
+func FormatLink(from, to string) string {
+	return formatLinkName(from) + "-" + formatLinkName(to)
+}
+
+func formatLinkName(name string) string {
+	if !strings.ContainsAny(name, `-"`) {
+		return name
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)
+	return `"` + escaped + `"`
+}
@@ -0,0 +1,1813 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lem-in/internal/resources"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTurns(t *testing.T) {
+	type args struct {
+		option map[int][]int
+		paths  []resources.Path
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "Single path",
+			args: args{
+				option: map[int][]int{0: {3}},
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"1", "2", "3"}},
+				},
+			},
+			want: 2,
+		},
+		{
+			name: "Multiple paths",
+			args: args{
+				option: map[int][]int{0: {2}, 1: {1}},
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"1", "2", "3"}},
+					{RoomsInThePath: []string{"1", "4", "3"}},
+				},
+			},
+			want: 2,
+		},
+		{
+			name: "Uneven distribution",
+			args: args{
+				option: map[int][]int{0: {3}, 1: {1}},
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"1", "2", "3"}},
+					{RoomsInThePath: []string{"1", "4", "5", "3"}},
+				},
+			},
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateTurns(tt.args.option, tt.args.paths)
+			if got != tt.want {
+				t.Errorf("GenerateTurns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileContents(t *testing.T) {
+	// Create a temporary directory for test files
+	tmpDir, err := os.MkdirTemp("", "filecontents_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir) // Clean up after the test
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "valid file with all types of lines",
+			content: `3
+##start
+1 23 3
+#comment to ignore
+2 16 7
+##end
+0 9 5
+`,
+			expected: []string{"3", "##start", "1 23 3", "2 16 7", "##end", "0 9 5"},
+			wantErr:  false,
+		},
+		{
+			name:     "empty file",
+			content:  ``,
+			expected: []string{},
+			wantErr:  false,
+		},
+		{
+			name: "only comments",
+			content: `#comment1
+#comment2
+#comment3`,
+			expected: []string{},
+			wantErr:  false,
+		},
+		{
+			name: "special commands and connections",
+			content: `##start
+##end
+1-2
+2-3`,
+			expected: []string{"##start", "##end", "1-2", "2-3"},
+			wantErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a temporary file for this test case
+			tmpFile := filepath.Join(tmpDir, "test.txt")
+			err := os.WriteFile(tmpFile, []byte(tt.content), 0644)
+			if err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			// Test the fileContents function
+			got, err := fileContents(tmpFile)
+
+			// Check error cases
+			if (err != nil) != tt.wantErr {
+				t.Errorf("fileContents() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			// Compare results
+			if len(got) != len(tt.expected) {
+				t.Errorf("fileContents() got %d lines, want %d lines", len(got), len(tt.expected))
+				return
+			}
+
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("fileContents() line %d = %q, want %q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+
+	// Test non-existent file
+	t.Run("non-existent file", func(t *testing.T) {
+		_, err := fileContents("/non/existent/file.txt")
+		if err == nil {
+			t.Error("fileContents() expected error for non-existent file, got nil")
+		}
+	})
+}
+
+func TestParseRoom(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		colony  *resources.AntColony
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "valid room",
+			line: "room1 23 45",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "room1",
+			wantErr: false,
+		},
+		{
+			name: "invalid format - too few parts",
+			line: "room1 23",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "invalid format - too many parts",
+			line: "room1 23 45 67",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "invalid X coordinate",
+			line: "room1 abc 45",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "invalid Y coordinate",
+			line: "room1 23 def",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "duplicate coordinates",
+			line: "room2 23 45",
+			colony: &resources.AntColony{
+				Rooms: []resources.Room{
+					{Name: "room1", Coord_X: 23, Coord_Y: 45},
+				},
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "multiple valid rooms",
+			line: "room2 24 46",
+			colony: &resources.AntColony{
+				Rooms: []resources.Room{
+					{Name: "room1", Coord_X: 23, Coord_Y: 45},
+				},
+			},
+			want:    "room2",
+			wantErr: false,
+		},
+		{
+			name: "invalid room name starts with L",
+			line: "L1 1 2",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "invalid room name starts with #",
+			line: "#room 1 2",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name: "invalid room name with space",
+			line: "room one 1 2",
+			colony: &resources.AntColony{
+				Rooms: make([]resources.Room, 0),
+			},
+			want:    "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRoom(tt.line, tt.colony)
+
+			// Check error cases
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRoom() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			// Check return value
+			if got != tt.want {
+				t.Errorf("parseRoom() = %v, want %v", got, tt.want)
+			}
+
+			// For valid cases, check if room was actually added to colony
+			if err == nil {
+				found := false
+				for _, room := range tt.colony.Rooms {
+					if room.Name == got {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("parseRoom() room %s was not added to colony", got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRoomName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{
+			name:    "Valid room name",
+			input:   "room1",
+			wantErr: false,
+		},
+		{
+			name:    "Room name starting with L",
+			input:   "L1",
+			wantErr: true,
+		},
+		{
+			name:    "Room name starting with #",
+			input:   "#room",
+			wantErr: true,
+		},
+		{
+			name:    "Room name with space",
+			input:   "room 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRoomName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRoomName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		colony  *resources.AntColony
+		wantErr bool
+	}{
+		{
+			name: "valid connection",
+			line: "room1-room2",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room1": {},
+					"room2": {},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid format - no hyphen",
+			line: "room1room2",
+			colony: &resources.AntColony{
+				Links: map[string][]string{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid format - multiple hyphens",
+			line: "room1-room2-room3",
+			colony: &resources.AntColony{
+				Links: map[string][]string{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "self connection",
+			line: "room1-room1",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room1": {},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "first room doesn't exist",
+			line: "nonexistent-room2",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room2": {},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "second room doesn't exist",
+			line: "room1-nonexistent",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room1": {},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate connection",
+			line: "room1-room2",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room1": {},
+					"room2": {},
+				},
+			},
+			wantErr: true,
+			// This will error because we'll try to add the same connection twice
+		},
+		{
+			name: "valid reverse connection",
+			line: "room2-room1",
+			colony: &resources.AntColony{
+				Links: map[string][]string{
+					"room1": {},
+					"room2": {},
+				},
+			},
+			wantErr: true, // Should error because room1-room2 was already added
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// For duplicate connection test, add the first connection
+			if tt.name == "duplicate connection" || tt.name == "valid reverse connection" {
+				tt.colony.Tunnels = []resources.Link{{From: "room1", To: "room2"}}
+			}
+
+			err := parseConnection(tt.line, tt.colony)
+
+			// Check error cases
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseConnection() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			// For valid cases, check if connection was actually added to colony
+			if err == nil {
+				parts := strings.Split(tt.line, "-")
+
+				// Check forward connection
+				found := false
+				for _, conn := range tt.colony.Links[parts[0]] {
+					if conn == parts[1] {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("parseConnection() forward connection %s->%s was not added", parts[0], parts[1])
+				}
+
+				// Check reverse connection
+				found = false
+				for _, conn := range tt.colony.Links[parts[1]] {
+					if conn == parts[0] {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("parseConnection() reverse connection %s->%s was not added", parts[1], parts[0])
+				}
+
+				// Check if the link was recorded for duplicate checks
+				link := resources.Link{From: parts[0], To: parts[1]}
+				link2 := resources.Link{From: parts[1], To: parts[0]}
+				if !tt.colony.Linked[link] || !tt.colony.Linked[link2] {
+					t.Errorf("parseConnection() links not properly added to Linked map")
+				}
+			}
+		})
+	}
+}
+
+func TestMoveAnts(t *testing.T) {
+	type args struct {
+		paths       []resources.Path
+		antsPerRoom map[int][]int
+		turns       int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Single path with one ant",
+			args: args{
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"start", "1", "end"}},
+				},
+				antsPerRoom: map[int][]int{
+					0: {1},
+				},
+				turns: 2,
+			},
+			want: []string{"L1-1", "L1-end"},
+		},
+		{
+			name: "Two paths with two ants",
+			args: args{
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"start", "1", "end"}},
+					{RoomsInThePath: []string{"start", "2", "end"}},
+				},
+				antsPerRoom: map[int][]int{
+					0: {1},
+					1: {2},
+				},
+				turns: 2,
+			},
+			want: []string{"L1-1 L2-2", "L1-end L2-end"},
+		},
+		{
+			name: "Single path with multiple ants",
+			args: args{
+				paths: []resources.Path{
+					{RoomsInThePath: []string{"start", "1", "2", "end"}},
+				},
+				antsPerRoom: map[int][]int{
+					0: {1, 2, 3},
+				},
+				turns: 5,
+			},
+			want: []string{"L1-1", "L1-2 L2-1", "L1-end L2-2 L3-1", "L2-end L3-2", "L3-end"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MoveAnts(tt.args.paths, tt.args.antsPerRoom, tt.args.turns)
+
+			// Trim trailing spaces from both `got` and `want` for consistency
+			for i := range got {
+				got[i] = strings.TrimSpace(got[i])
+			}
+			for i := range tt.want {
+				tt.want[i] = strings.TrimSpace(tt.want[i])
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MoveAnts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPaths(t *testing.T) {
+	tests := []struct {
+		name            string
+		colony          *resources.AntColony
+		wantPaths       []resources.Path
+		wantAntsPerPath map[int][]int
+		wantTurns       int
+	}{
+		{
+			name: "Simple path with one route",
+			colony: &resources.AntColony{
+				NumberOfAnts: 3,
+				Start:        "1",
+				End:          "0",
+				Rooms: []resources.Room{
+					{Name: "1"},
+					{Name: "2"},
+					{Name: "0"},
+				},
+				Links: map[string][]string{
+					"1": {"2"},
+					"2": {"1", "0"},
+					"0": {"2"},
+				},
+			},
+			wantPaths: []resources.Path{
+				{RoomsInThePath: []string{"1", "2", "0"}},
+			},
+			wantAntsPerPath: map[int][]int{
+				0: {1, 2, 3},
+			},
+			wantTurns: 4,
+		},
+		{
+			name: "Multiple possible paths",
+			colony: &resources.AntColony{
+				NumberOfAnts: 4,
+				Start:        "start",
+				End:          "end",
+				Rooms: []resources.Room{
+					{Name: "start"},
+					{Name: "room1"},
+					{Name: "room2"},
+					{Name: "room3"},
+					{Name: "end"},
+				},
+				Links: map[string][]string{
+					"start": {"room1", "room2"},
+					"room1": {"start", "room3"},
+					"room2": {"start", "end"},
+					"room3": {"room1", "end"},
+					"end":   {"room2", "room3"},
+				},
+			},
+			wantPaths: []resources.Path{
+				{RoomsInThePath: []string{"start", "room2", "end"}},
+				{RoomsInThePath: []string{"start", "room1", "room3", "end"}},
+			},
+			wantAntsPerPath: map[int][]int{
+				0: {1, 2, 4},
+				1: {3},
+			},
+			wantTurns: 4,
+		},
+		{
+			name: "Path with cycle detection",
+			colony: &resources.AntColony{
+				NumberOfAnts: 2,
+				Start:        "start",
+				End:          "end",
+				Rooms: []resources.Room{
+					{Name: "start"},
+					{Name: "A"},
+					{Name: "B"},
+					{Name: "end"},
+				},
+				Links: map[string][]string{
+					"start": {"A", "B"},
+					"A":     {"start", "B"},
+					"B":     {"A", "start", "end"},
+					"end":   {"B"},
+				},
+			},
+			wantPaths: []resources.Path{
+				{RoomsInThePath: []string{"start", "B", "end"}},
+			},
+			wantAntsPerPath: map[int][]int{
+				0: {1, 2},
+			},
+			wantTurns: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPaths, gotAntsPerPath, gotTurns := FindPaths(tt.colony)
+
+			// Check number of paths
+			if len(gotPaths) != len(tt.wantPaths) {
+				t.Errorf("FindPaths() got %v paths, want %v paths", len(gotPaths), len(tt.wantPaths))
+				return
+			}
+
+			// Check each path
+			for i, wantPath := range tt.wantPaths {
+				if !reflect.DeepEqual(gotPaths[i].RoomsInThePath, wantPath.RoomsInThePath) {
+					t.Errorf("FindPaths() path %d = %v, want %v", i, gotPaths[i].RoomsInThePath, wantPath.RoomsInThePath)
+				}
+			}
+
+			// Check ants per path distribution
+			if !reflect.DeepEqual(gotAntsPerPath, tt.wantAntsPerPath) {
+				t.Errorf("FindPaths() ants distribution = %v, want %v", gotAntsPerPath, tt.wantAntsPerPath)
+			}
+
+			// Check number of turns
+			if gotTurns != tt.wantTurns {
+				t.Errorf("FindPaths() turns = %v, want %v", gotTurns, tt.wantTurns)
+			}
+		})
+	}
+}
+
+func TestContainsRoom(t *testing.T) {
+	tests := []struct {
+		name string
+		path []string
+		room string
+		want bool
+	}{
+		{
+			name: "Room exists in path",
+			path: []string{"start", "room1", "room2", "end"},
+			room: "room1",
+			want: true,
+		},
+		{
+			name: "Room does not exist in path",
+			path: []string{"start", "room1", "room2", "end"},
+			room: "room3",
+			want: false,
+		},
+		{
+			name: "Empty path",
+			path: []string{},
+			room: "room1",
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsRoom(tt.path, tt.room); got != tt.want {
+				t.Errorf("containsRoom() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Paths in the OptimizedPaths tests are room IDs, with 0 for the start room and 1 for the end room.
+func TestOptimizedPaths1(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths [][]int
+		want  [][]int
+	}{
+		{
+			name: "Non-overlapping paths",
+			paths: [][]int{
+				{0, 2, 1},
+				{0, 3, 1},
+			},
+			want: [][]int{
+				{0, 2, 1},
+				{0, 3, 1},
+			},
+		},
+		{
+			name: "Overlapping paths",
+			paths: [][]int{
+				{0, 2, 1},
+				{0, 2, 3, 1},
+			},
+			want: [][]int{
+				{0, 2, 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OptimizedPaths1(tt.paths)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OptimizedPaths1() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptimizedPaths2(t *testing.T) {
+	tests := []struct {
+		name   string
+		paths  [][]int
+		colony *resources.AntColony
+		want   [][]int
+	}{
+		{
+			name: "Paths within ant count limit",
+			paths: [][]int{
+				{0, 2, 1},
+				{0, 3, 1},
+			},
+			colony: &resources.AntColony{
+				NumberOfAnts: 4,
+			},
+			want: [][]int{
+				{0, 2, 1},
+				{0, 3, 1},
+			},
+		},
+		{
+			name: "Paths exceeding ant count limit",
+			paths: [][]int{
+				{0, 2, 1},
+				{0, 3, 4, 5, 1},
+			},
+			colony: &resources.AntColony{
+				NumberOfAnts: 2,
+			},
+			want: [][]int{
+				{0, 2, 1},
+			},
+		},
+		{
+			name: "Replacement sharing rooms with another path",
+			paths: [][]int{
+				{0, 2, 3, 6, 1},
+				{0, 4, 1},
+				{0, 2, 4, 1},
+			},
+			colony: &resources.AntColony{
+				NumberOfAnts: 10,
+			},
+			want: [][]int{
+				{0, 2, 3, 6, 1},
+				{0, 4, 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OptimizedPaths2(tt.paths, tt.colony)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OptimizedPaths2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceAnts(t *testing.T) {
+	tests := []struct {
+		name   string
+		colony *resources.AntColony
+		paths  []resources.Path
+		want   map[int][]int
+	}{
+		{
+			name: "Single path",
+			colony: &resources.AntColony{
+				NumberOfAnts: 3,
+				Start:        "start",
+				End:          "end",
+			},
+			paths: []resources.Path{
+				{RoomsInThePath: []string{"start", "1", "end"}},
+			},
+			want: map[int][]int{
+				0: {1, 2, 3},
+			},
+		},
+		{
+			name: "Two equal length paths",
+			colony: &resources.AntColony{
+				NumberOfAnts: 4,
+				Start:        "start",
+				End:          "end",
+			},
+			paths: []resources.Path{
+				{RoomsInThePath: []string{"start", "1", "end"}},
+				{RoomsInThePath: []string{"start", "2", "end"}},
+			},
+			want: map[int][]int{
+				0: {1, 3},
+				1: {2, 4},
+			},
+		},
+		{
+			name: "Two paths with different lengths",
+			colony: &resources.AntColony{
+				NumberOfAnts: 5,
+				Start:        "start",
+				End:          "end",
+			},
+			paths: []resources.Path{
+				{RoomsInThePath: []string{"start", "1", "end"}},
+				{RoomsInThePath: []string{"start", "2", "3", "end"}},
+			},
+			want: map[int][]int{
+				0: {1, 2, 4},
+				1: {3, 5},
+			},
+		},
+		{
+			name: "Three paths with varying lengths",
+			colony: &resources.AntColony{
+				NumberOfAnts: 6,
+				Start:        "start",
+				End:          "end",
+			},
+			paths: []resources.Path{
+				{RoomsInThePath: []string{"start", "1", "end"}},
+				{RoomsInThePath: []string{"start", "2", "3", "end"}},
+				{RoomsInThePath: []string{"start", "4", "5", "6", "end"}},
+			},
+			want: map[int][]int{
+				0: {1, 2, 4, 6},
+				1: {3, 5},
+			},
+		},
+		{
+			name: "No ants",
+			colony: &resources.AntColony{
+				NumberOfAnts: 0,
+				Start:        "start",
+				End:          "end",
+			},
+			paths: []resources.Path{
+				{RoomsInThePath: []string{"start", "1", "end"}},
+			},
+			want: map[int][]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PlaceAnts(tt.colony, tt.paths)
+
+			// Check if the number of paths with assignments matches
+			if len(got) != len(tt.want) {
+				t.Errorf("PlaceAnts() got %d paths with assignments, want %d", len(got), len(tt.want))
+				return
+			}
+
+			// Check each path's ant assignments
+			for pathIndex, wantAnts := range tt.want {
+				gotAnts, exists := got[pathIndex]
+				if !exists {
+					t.Errorf("PlaceAnts() missing assignments for path %d", pathIndex)
+					continue
+				}
+
+				// Check number of ants assigned to this path
+				if len(gotAnts) != len(wantAnts) {
+					t.Errorf("PlaceAnts() path %d got %d ants, want %d ants", pathIndex, len(gotAnts), len(wantAnts))
+					continue
+				}
+
+				// Check if all expected ants are present
+				for i, wantAnt := range wantAnts {
+					if gotAnts[i] != wantAnt {
+						t.Errorf("PlaceAnts() path %d, ant index %d got ant %d, want ant %d",
+							pathIndex, i, gotAnts[i], wantAnt)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWriteMoves(t *testing.T) {
+	paths := []resources.Path{
+		{RoomsInThePath: []string{"start", "1", "end"}},
+		{RoomsInThePath: []string{"start", "2", "3", "end"}},
+	}
+	tests := []struct {
+		name        string
+		antsPerPath map[int][]int
+		turns       int
+		want        string
+	}{
+		{
+			name:        "Single ant",
+			antsPerPath: map[int][]int{0: {1}},
+			turns:       2,
+			want:        "L1-1\nL1-end\n",
+		},
+		{
+			name:        "Two paths with five ants",
+			antsPerPath: map[int][]int{0: {1, 2, 4}, 1: {3, 5}},
+			turns:       4,
+			want:        "L1-1 L3-2\nL1-end L2-1 L3-3 L5-2\nL2-end L4-1 L3-end L5-3\nL4-end L5-end\n",
+		},
+		{
+			name:        "No turns",
+			antsPerPath: map[int][]int{},
+			turns:       0,
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteMoves(&sb, paths, tt.antsPerPath, tt.turns); err != nil {
+				t.Fatalf("WriteMoves() error = %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("WriteMoves() = %q, want %q", sb.String(), tt.want)
+			}
+
+			// The streamed output must match the slice based MoveAnts
+			moves := MoveAnts(paths, tt.antsPerPath, tt.turns)
+			joined := ""
+			for _, move := range moves {
+				joined += move + "\n"
+			}
+			if joined != tt.want {
+				t.Errorf("MoveAnts() = %q, want %q", joined, tt.want)
+			}
+		})
+	}
+}
+
+func TestSolveTimeExpanded(t *testing.T) {
+	tests := []struct {
+		name    string
+		colony  *resources.AntColony
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "Single path",
+			colony: &resources.AntColony{
+				NumberOfAnts: 3,
+				Start:        "start",
+				End:          "end",
+				Links: map[string][]string{
+					"start": {"1"},
+					"1":     {"start", "2"},
+					"2":     {"1", "end"},
+					"end":   {"2"},
+				},
+			},
+			want: 5,
+		},
+		{
+			name: "Two disjoint paths",
+			colony: &resources.AntColony{
+				NumberOfAnts: 4,
+				Start:        "start",
+				End:          "end",
+				Links: map[string][]string{
+					"start": {"room1", "room2"},
+					"room1": {"start", "room3"},
+					"room2": {"start", "end"},
+					"room3": {"room1", "end"},
+					"end":   {"room2", "room3"},
+				},
+			},
+			want: 4,
+		},
+		{
+			name: "Direct tunnel from start to end",
+			colony: &resources.AntColony{
+				NumberOfAnts: 5,
+				Start:        "start",
+				End:          "end",
+				Links: map[string][]string{
+					"start": {"end"},
+					"end":   {"start"},
+				},
+			},
+			want: 5,
+		},
+		{
+			name: "Shared bottleneck room",
+			colony: &resources.AntColony{
+				NumberOfAnts: 3,
+				Start:        "start",
+				End:          "end",
+				Links: map[string][]string{
+					"start": {"A", "B"},
+					"A":     {"start", "C"},
+					"B":     {"start", "C"},
+					"C":     {"A", "B", "end"},
+					"end":   {"C"},
+				},
+			},
+			want: 5,
+		},
+		{
+			name: "End not reachable",
+			colony: &resources.AntColony{
+				NumberOfAnts: 1,
+				Start:        "start",
+				End:          "end",
+				Links: map[string][]string{
+					"start": {"A"},
+					"A":     {"start"},
+					"end":   {},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SolveTimeExpanded(tt.colony)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SolveTimeExpanded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SolveTimeExpanded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The exact solver must never need more turns than the heuristic
+	t.Run("example00 bound", func(t *testing.T) {
+		colony, err := ParseFile(filepath.Join("..", "..", "cmd", "example00.txt"))
+		if err != nil {
+			t.Fatalf("ParseFile() error = %v", err)
+		}
+		_, _, heuristic := FindPaths(colony)
+		got, err := SolveTimeExpanded(colony)
+		if err != nil {
+			t.Fatalf("SolveTimeExpanded() error = %v", err)
+		}
+		if got > heuristic {
+			t.Errorf("SolveTimeExpanded() = %v, heuristic found %v", got, heuristic)
+		}
+	})
+}
+
+func TestSplitLink(t *testing.T) {
+	colony := &resources.AntColony{
+		Links: map[string][]string{
+			"a":          {},
+			"b":          {},
+			"north-gate": {},
+			"hall":       {},
+			"x-y":        {},
+			"x":          {},
+			"y-z":        {},
+			"z":          {},
+			`say"hi`:     {},
+		},
+	}
+	tests := []struct {
+		name     string
+		line     string
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{name: "Classic link", line: "a-b", wantFrom: "a", wantTo: "b"},
+		{name: "Quoted names", line: `"north-gate"-"hall"`, wantFrom: "north-gate", wantTo: "hall"},
+		{name: "Quoted and bare name", line: `hall-"north-gate"`, wantFrom: "hall", wantTo: "north-gate"},
+		{name: "Escaped quote", line: `"say\"hi"-a`, wantFrom: `say"hi`, wantTo: "a"},
+		{name: "Unique bare split", line: "north-gate-hall", wantFrom: "north-gate", wantTo: "hall"},
+		{name: "Ambiguous bare split", line: "x-y-z", wantErr: true},
+		{name: "No matching split", line: "a-b-c", wantErr: true},
+		{name: "Missing second room", line: "a-", wantErr: true},
+		{name: "Unterminated quote", line: `"north-gate-hall`, wantErr: true},
+		{name: "Empty quoted name", line: `""-a`, wantErr: true},
+		{name: "Invalid escape", line: `"a\n"-b`, wantErr: true},
+		{name: "Trailing text", line: `"a"-"b"c`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := splitLink(tt.line, colony)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("splitLink() = %q, %q, want %q, %q", from, to, tt.wantFrom, tt.wantTo)
+			}
+			if err == nil {
+				// Formatting the link must read back as the same rooms
+				from, to, err = splitLink(FormatLink(from, to), colony)
+				if err != nil || from != tt.wantFrom || to != tt.wantTo {
+					t.Errorf("splitLink(FormatLink()) = %q, %q, %v", from, to, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathsLargeGrid(t *testing.T) {
+	// A grid has far too many simple paths to enumerate, so this exercises the
+	// search budget and the augmenting path fallback.
+	const size = 150
+	colony := &resources.AntColony{
+		NumberOfAnts: 200,
+		Start:        "r0_0",
+		End:          fmt.Sprintf("r%d_%d", size-1, size-1),
+		Links:        make(map[string][]string),
+	}
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			name := fmt.Sprintf("r%d_%d", i, j)
+			colony.Rooms = append(colony.Rooms, resources.Room{Name: name, Coord_X: i, Coord_Y: j})
+			if i > 0 {
+				up := fmt.Sprintf("r%d_%d", i-1, j)
+				colony.Links[name] = append(colony.Links[name], up)
+				colony.Links[up] = append(colony.Links[up], name)
+			}
+			if j > 0 {
+				left := fmt.Sprintf("r%d_%d", i, j-1)
+				colony.Links[name] = append(colony.Links[name], left)
+				colony.Links[left] = append(colony.Links[left], name)
+			}
+		}
+	}
+
+	paths, antsPerPath, turns := FindPaths(colony)
+	if len(paths) != 2 {
+		t.Errorf("FindPaths() found %d paths, want 2", len(paths))
+	}
+	if err := VerifyMoves(colony, MoveAnts(paths, antsPerPath, turns)); err != nil {
+		t.Errorf("FindPaths() produced invalid moves: %v", err)
+	}
+}
+
+func TestParseModes(t *testing.T) {
+	const classic = "2\n##start\nstart 0 0\n##end\nend 1 0\nstart-end\n"
+	tests := []struct {
+		name    string
+		input   string
+		mode    ParseMode
+		wantErr bool
+	}{
+		{name: "Classic map default", input: classic, mode: ModeDefault},
+		{name: "Classic map strict", input: classic, mode: ModeStrict},
+		{name: "Classic map lenient", input: classic, mode: ModeLenient},
+		{name: "CRLF default", input: strings.ReplaceAll(classic, "\n", "\r\n"), mode: ModeDefault},
+		{name: "CRLF strict", input: strings.ReplaceAll(classic, "\n", "\r\n"), mode: ModeStrict},
+		{name: "CRLF lenient", input: strings.ReplaceAll(classic, "\n", "\r\n"), mode: ModeLenient},
+		{name: "BOM default", input: "\uFEFF" + classic, mode: ModeDefault, wantErr: true},
+		{name: "BOM lenient", input: "\uFEFF" + classic, mode: ModeLenient},
+		{name: "Tabs default", input: "2\n##start\nstart\t0\t0\n##end\nend 1 0\nstart-end\n", mode: ModeDefault},
+		{name: "Tabs strict", input: "2\n##start\nstart\t0\t0\n##end\nend 1 0\nstart-end\n", mode: ModeStrict, wantErr: true},
+		{name: "Tabs lenient", input: "2\n##start\nstart\t0\t0\n##end\nend 1 0\nstart-end\n", mode: ModeLenient},
+		{name: "Trailing spaces default", input: "2\n##start \nstart 0 0\n##end\nend 1 0\nstart-end\n", mode: ModeDefault},
+		{name: "Trailing spaces strict", input: "2\n##start \nstart 0 0\n##end\nend 1 0\nstart-end\n", mode: ModeStrict, wantErr: true},
+		{name: "Trailing spaces on link lenient", input: "2\n##start\nstart 0 0\n##end\nend 1 0\nstart-end  \n", mode: ModeLenient},
+		{name: "Uppercase directives default", input: "2\n##START\nstart 0 0\n##End\nend 1 0\nstart-end\n", mode: ModeDefault, wantErr: true},
+		{name: "Uppercase directives lenient", input: "2\n##START\nstart 0 0\n##End\nend 1 0\nstart-end\n", mode: ModeLenient},
+		{name: "Unknown command default", input: "2\n##start\nstart 0 0\n##hazard\n##end\nend 1 0\nstart-end\n", mode: ModeDefault},
+		{name: "Unknown command strict", input: "2\n##start\nstart 0 0\n##hazard\n##end\nend 1 0\nstart-end\n", mode: ModeStrict, wantErr: true},
+		{name: "Room after links default", input: "2\n##start\nstart 0 0\n##end\nend 1 0\nstart-end\nextra 2 2\n", mode: ModeDefault},
+		{name: "Room after links strict", input: "2\n##start\nstart 0 0\n##end\nend 1 0\nstart-end\nextra 2 2\n", mode: ModeStrict, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colony, err := Parse(strings.NewReader(tt.input), ParseOptions{Mode: tt.mode})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (colony.Start != "start" || colony.End != "end" || colony.NumberOfAnts != 2) {
+				t.Errorf("Parse() = %+v", colony)
+			}
+		})
+	}
+}
+
+func TestParseAllErrors(t *testing.T) {
+	input := "x\n##start\ns 0 0\nLbad 1 1\na 0 0\n# comment\ns-nowhere\na-\n"
+	tests := []struct {
+		name      string
+		input     string
+		allErrors bool
+		wantLines []int
+		wantErrs  []string
+	}{
+		{name: "Stop at first error", input: input, allErrors: false, wantLines: []int{1}},
+		{name: "Collect every error", input: input, allErrors: true, wantLines: []int{1, 4, 5, 7, 8, 0}},
+		{
+			name:      "Missing start and end",
+			input:     "2\na 0 0\nb 1 0\na-b\n",
+			allErrors: true,
+			wantLines: []int{0, 0},
+			wantErrs:  []string{"no start room found", "no end room found"},
+		},
+		{
+			name:      "Missing start and end, first only",
+			input:     "2\na 0 0\nb 1 0\na-b\n",
+			wantLines: []int{0},
+			wantErrs:  []string{"no start room found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input), ParseOptions{AllErrors: tt.allErrors})
+			if err == nil {
+				t.Fatal("Parse() error = nil")
+			}
+
+			var errs []error
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				errs = joined.Unwrap()
+			} else {
+				errs = []error{err}
+			}
+			var gotLines []int
+			var gotErrs []string
+			for _, e := range errs {
+				parseErr, ok := e.(*ParseError)
+				if !ok {
+					t.Fatalf("Parse() error %v is not a *ParseError", e)
+				}
+				gotLines = append(gotLines, parseErr.Line)
+				gotErrs = append(gotErrs, parseErr.Err.Error())
+			}
+			if !reflect.DeepEqual(gotLines, tt.wantLines) {
+				t.Errorf("Parse() error lines = %v, want %v (%v)", gotLines, tt.wantLines, err)
+			}
+			if tt.wantErrs != nil && !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("Parse() errors = %q, want %q", gotErrs, tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	registry := NewDirectiveRegistry()
+	err := registry.Register("capacity", func(args, next string, colony *resources.AntColony) error {
+		if _, err := strconv.Atoi(args); err != nil {
+			return fmt.Errorf("invalid capacity: %s", args)
+		}
+		if !strings.Contains(next, " ") {
+			return fmt.Errorf("must be followed by a room")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register("start", nil); err == nil {
+		t.Error("Register() replaced ##start")
+	}
+	if err := registry.Register("capacity", nil); err == nil {
+		t.Error("Register() registered ##capacity twice")
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		opts          ParseOptions
+		wantErr       bool
+		wantDirective map[string]string
+	}{
+		{
+			name:          "Registered directive",
+			input:         "1\n##start\ns 0 0\n##capacity 3\n##hazard\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:          ParseOptions{Directives: registry},
+			wantDirective: map[string]string{"capacity": "3"},
+		},
+		{
+			name:    "Handler error",
+			input:   "1\n##start\ns 0 0\n##capacity many\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:    ParseOptions{Directives: registry},
+			wantErr: true,
+		},
+		{
+			name:  "Unregistered directive is a comment",
+			input: "1\n##start\ns 0 0\n##capacity 3\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:  ParseOptions{},
+		},
+		{
+			name:    "Unknown directive reported",
+			input:   "1\n##start\ns 0 0\n##hazard\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:    ParseOptions{Directives: registry, ReportUnknownDirectives: true},
+			wantErr: true,
+		},
+		{
+			name:          "Registered directive in strict mode",
+			input:         "1\n##start\ns 0 0\n##capacity 3\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:          ParseOptions{Mode: ModeStrict, Directives: registry},
+			wantDirective: map[string]string{"capacity": "3"},
+		},
+		{
+			name:          "Registered directive in any letter case in lenient mode",
+			input:         "1\n##start\ns 0 0\n##Capacity 3\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n",
+			opts:          ParseOptions{Mode: ModeLenient, Directives: registry},
+			wantDirective: map[string]string{"capacity": "3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colony, err := Parse(strings.NewReader(tt.input), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, room := range colony.Rooms {
+				if room.Name != "mid" {
+					continue
+				}
+				if !reflect.DeepEqual(room.Directives, tt.wantDirective) {
+					t.Errorf("room mid directives = %v, want %v", room.Directives, tt.wantDirective)
+				}
+			}
+			if tt.wantDirective != nil && !strings.Contains(colony.Source, "##capacity 3\n") {
+				t.Errorf("registered directive was not echoed: %q", colony.Source)
+			}
+		})
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	const rooms = "1\n##start\ns 0 0\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n"
+	tests := []struct {
+		name     string
+		input    string
+		mode     ParseMode
+		wantErr  bool
+		wantMeta map[string]string
+	}{
+		{
+			name:     "Bare and quoted values",
+			input:    rooms + `#@ room=mid label="Main gate" tag=chokepoint` + "\n",
+			wantMeta: map[string]string{"label": "Main gate", "tag": "chokepoint"},
+		},
+		{
+			name:     "Annotation before the room",
+			input:    "#@ room=mid color=#ff8800\n" + rooms,
+			wantMeta: map[string]string{"color": "#ff8800"},
+		},
+		{
+			name:     "Later values replace earlier ones",
+			input:    rooms + "#@ room=mid tag=a\n#@ room=mid tag=b\n",
+			wantMeta: map[string]string{"tag": "b"},
+		},
+		{
+			name:     "Escapes",
+			input:    rooms + `#@ room=mid label="say \"hi\" \\ bye"` + "\n",
+			wantMeta: map[string]string{"label": `say "hi" \ bye`},
+		},
+		{
+			name:    "Unknown room",
+			input:   rooms + "#@ room=nowhere tag=x\n",
+			wantErr: true,
+		},
+		{
+			name:  "Note is a comment",
+			input: rooms + "#@ this is just a note\n",
+		},
+		{
+			name:  "Missing room is a comment",
+			input: rooms + "#@ tag=x\n",
+		},
+		{
+			name:  "Unterminated quote is a comment",
+			input: rooms + `#@ room=mid label="open` + "\n",
+		},
+		{
+			name:    "Note in strict mode",
+			input:   rooms + "#@ this is just a note\n",
+			mode:    ModeStrict,
+			wantErr: true,
+		},
+		{
+			name:    "Missing room in strict mode",
+			input:   rooms + "#@ tag=x\n",
+			mode:    ModeStrict,
+			wantErr: true,
+		},
+		{
+			name:    "Unterminated quote in strict mode",
+			input:   rooms + `#@ room=mid label="open` + "\n",
+			mode:    ModeStrict,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colony, err := Parse(strings.NewReader(tt.input), ParseOptions{Mode: tt.mode})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := colony.Rooms[1].Meta; !reflect.DeepEqual(got, tt.wantMeta) {
+				t.Errorf("room mid meta = %v, want %v", got, tt.wantMeta)
+			}
+		})
+	}
+}
+
+func TestFormatAnnotation(t *testing.T) {
+	meta := map[string]string{"tag": "chokepoint", "label": `Main "gate"`, "note": "a=b"}
+	line := FormatAnnotation("mid", meta)
+	if want := `#@ room=mid label="Main \"gate\"" note="a=b" tag=chokepoint`; line != want {
+		t.Errorf("FormatAnnotation() = %s, want %s", line, want)
+	}
+
+	room, got, err := parseAnnotation(line)
+	if err != nil || room != "mid" || !reflect.DeepEqual(got, meta) {
+		t.Errorf("parseAnnotation(%s) = %s, %v, %v", line, room, got, err)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"pair.map":       "a 0 0\nb 1 0\n#@ room=b tag=exit\na-b\n",
+		"nested.map":     "##include pair.map prefix=p_ offset=0,5\nx-y 3 3\n",
+		"sub/hyphen.map": "one-way 0 0\ntwo 1 0\none-way-two\n",
+		"cycle.map":      "##include cycle2.map\n",
+		"cycle2.map":     "##include cycle.map\n",
+		"bad.map":        "a 0 0\n\nbad 1\n",
+		"late.map":       "a 0 0\nb 1 0\na-b\nc 2 0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const ends = "1\n##start\ns 10 10\n##end\ne 11 10\n"
+
+	tests := []struct {
+		name      string
+		input     string
+		opts      ParseOptions
+		wantRooms map[string][2]int
+		wantMeta  map[string]string
+		wantErr   string
+	}{
+		{
+			name:      "Prefix and offset",
+			input:     ends + "##include pair.map prefix=n_ offset=2,3\ns-n_a\nn_b-e\n",
+			wantRooms: map[string][2]int{"n_a": {2, 3}, "n_b": {3, 3}},
+			wantMeta:  map[string]string{"n_b": "exit"},
+		},
+		{
+			name:      "Nested includes add up",
+			input:     ends + "##include nested.map prefix=n_ offset=1,0\ns-n_p_a\nn_p_b-e\n",
+			wantRooms: map[string][2]int{"n_p_a": {1, 5}, "n_p_b": {2, 5}},
+		},
+		{
+			name:      "Resolved relative to the including file with hyphenated names",
+			input:     ends + `##include "sub/hyphen.map" prefix=h-` + "\ns-h-one-way\nh-two-e\n",
+			wantRooms: map[string][2]int{"h-one-way": {0, 0}, "h-two": {1, 0}},
+		},
+		{
+			name:      "Lenient mode with an uppercase command",
+			input:     ends + "##Include pair.map prefix=N_\ns-N_a\nN_b-e\n",
+			opts:      ParseOptions{Mode: ModeLenient},
+			wantRooms: map[string][2]int{"N_a": {0, 0}, "N_b": {1, 0}},
+		},
+		{
+			name:      "Absolute path",
+			input:     ends + `##include "` + filepath.Join(dir, "sub", "hyphen.map") + `"` + "\ns-one-way\ntwo-e\n",
+			wantRooms: map[string][2]int{"one-way": {0, 0}, "two": {1, 0}},
+		},
+		{
+			name:      "Strict mode",
+			input:     ends + "##include pair.map\ns-a\nb-e\n",
+			opts:      ParseOptions{Mode: ModeStrict},
+			wantRooms: map[string][2]int{"a": {0, 0}, "b": {1, 0}},
+		},
+		{
+			name:      "Strict mode with a file included twice",
+			input:     ends + "##include pair.map prefix=x_\n##include pair.map prefix=y_ offset=0,1\ns-x_a\nx_b-y_a\ny_b-e\n",
+			opts:      ParseOptions{Mode: ModeStrict},
+			wantRooms: map[string][2]int{"x_a": {0, 0}, "y_b": {1, 1}},
+		},
+		{
+			name:    "Strict mode with an include after links",
+			input:   ends + "s-e\n##include pair.map\n",
+			opts:    ParseOptions{Mode: ModeStrict},
+			wantErr: "line 7: room or command after links: ##include pair.map",
+		},
+		{
+			name:    "Strict mode with a room after links in the included file",
+			input:   ends + "##include late.map\ns-a\nb-e\n",
+			opts:    ParseOptions{Mode: ModeStrict},
+			wantErr: "late.map: line 4: room or command after links: c 2 0",
+		},
+		{
+			name:    "Cycle",
+			input:   ends + "##include cycle.map\n",
+			wantErr: "cycle2.map: line 1: include cycle: main.txt -> ",
+		},
+		{
+			name:    "Missing file",
+			input:   ends + "##include missing.map\n",
+			wantErr: "line 6: ##include: open",
+		},
+		{
+			name:    "Unknown option",
+			input:   ends + "##include pair.map as=x\n",
+			wantErr: "line 6: unknown ##include option: as",
+		},
+		{
+			name:    "Error in the included file",
+			input:   ends + "##include bad.map\n",
+			wantErr: "bad.map: line 3: invalid room: invalid room format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			main := filepath.Join(dir, "main.txt")
+			if err := os.WriteFile(main, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			tt.opts.Filename = main
+			file, err := os.Open(main)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			colony, err := Parse(file, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			for _, room := range colony.Rooms {
+				if want, ok := tt.wantRooms[room.Name]; ok {
+					if got := [2]int{room.Coord_X, room.Coord_Y}; got != want {
+						t.Errorf("room %s at %v, want %v", room.Name, got, want)
+					}
+					delete(tt.wantRooms, room.Name)
+				}
+				if want, ok := tt.wantMeta[room.Name]; ok && room.Meta["tag"] != want {
+					t.Errorf("room %s tag = %q, want %q", room.Name, room.Meta["tag"], want)
+				}
+			}
+			if len(tt.wantRooms) > 0 {
+				t.Errorf("missing rooms %v", tt.wantRooms)
+			}
+		})
+	}
+}
+
+func TestFindPathsTraced(t *testing.T) {
+	colony, err := Parse(strings.NewReader("4\n##start\ns 0 0\na 1 0\nb 1 1\nc 2 1\n##end\ne 2 0\ns-a\na-e\ns-b\nb-c\nc-e\na-c\n"), ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var log bytes.Buffer
+	trace := slog.New(slog.NewJSONHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	paths, _, turns := FindPathsWith(colony, SolveOptions{Trace: trace})
+	wantPaths, _, wantTurns := FindPaths(colony)
+	if !reflect.DeepEqual(paths, wantPaths) || turns != wantTurns {
+		t.Errorf("FindPathsWith() = %v, %d, want the result of FindPaths %v, %d", paths, turns, wantPaths, wantTurns)
+	}
+
+	events := make(map[string]int)
+	decoder := json.NewDecoder(&log)
+	for decoder.More() {
+		var record struct{ Msg string }
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("trace is not JSON lines: %v", err)
+		}
+		events[record.Msg]++
+	}
+	for msg, want := range map[string]int{"search": 1, "discard": 1, "placement": 3, "choose": 1, "augment": 2, "final": 1} {
+		if events[msg] < want {
+			t.Errorf("trace has %d %q events, want at least %d: %v", events[msg], msg, want, events)
+		}
+	}
+
+	// A logger above debug level disables tracing
+	quiet := slog.New(slog.NewJSONHandler(&log, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	FindPathsWith(colony, SolveOptions{Trace: quiet})
+	if log.Len() != 0 {
+		t.Errorf("trace written at info level: %s", log.String())
+	}
+}
+
+func TestOutline(t *testing.T) {
+	text := "3\n##start\nstart 0 0\nbig-hall 1 2\n##end\nend 3 0\n#@ room=big-hall tag=hub\nstart-\"big-hall\"\n\"big-hall\"-end\n  start-end\n"
+	o := NewOutline(text)
+
+	if len(o.Rooms) != 3 || len(o.Refs) != 7 {
+		t.Fatalf("NewOutline() found %d rooms and %d references, want 3 and 7", len(o.Rooms), len(o.Refs))
+	}
+	if room, ok := o.Room("big-hall"); !ok || room.X != 1 || room.Y != 2 || room.Span != (Span{Line: 3, Start: 0, End: 8}) {
+		t.Errorf("Room(big-hall) = %+v, %v", room, ok)
+	}
+	if got := o.Degree("start"); got != 2 {
+		t.Errorf("Degree(start) = %d, want 2", got)
+	}
+
+	tests := []struct {
+		line, col int
+		want      string
+	}{
+		{7, 0, "start"},
+		{7, 8, "big-hall"},
+		{8, 12, "end"},
+		{9, 4, "start"},
+		{6, 9, "big-hall"},
+		{0, 0, ""},
+	}
+	for _, test := range tests {
+		if got, _ := o.NameAt(test.line, test.col); got != test.want {
+			t.Errorf("NameAt(%d, %d) = %q, want %q", test.line, test.col, got, test.want)
+		}
+	}
+
+	edits, err := o.Rename("big-hall", "hall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TextEdit{
+		{Span{Line: 3, Start: 0, End: 8}, "hall"},
+		{Span{Line: 6, Start: 8, End: 16}, "hall"},
+		{Span{Line: 7, Start: 6, End: 16}, "hall"},
+		{Span{Line: 8, Start: 0, End: 10}, "hall"},
+	}
+	if !reflect.DeepEqual(edits, want) {
+		t.Errorf("Rename() = %+v, want %+v", edits, want)
+	}
+	if _, err := o.Rename("hall", "end"); err == nil {
+		t.Error("Rename() to an existing room succeeded")
+	}
+	if _, err := o.Rename("end", "Lend"); err == nil {
+		t.Error("Rename() to an invalid name succeeded")
+	}
+}
+
+func TestCurve(t *testing.T) {
+	for _, name := range []string{"example00.txt", "example01.txt", "example02.txt", "example03.txt", "example04.txt", "big_corridors.txt", "hyphens.txt"} {
+		t.Run(name, func(t *testing.T) {
+			colony, err := ParseFile(filepath.Join("..", "..", "testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			const maxAnts = 60
+			points := Curve(colony, maxAnts)
+			if len(points) != maxAnts {
+				t.Fatalf("Curve() returned %d points, want %d", len(points), maxAnts)
+			}
+			// Every point must match a full solve with that many ants
+			for _, point := range points {
+				withAnts := *colony
+				withAnts.NumberOfAnts = point.Ants
+				paths, assignment, turns := FindPaths(&withAnts)
+				if point.Turns != turns {
+					t.Errorf("%d ants: Curve() gives %d turns, FindPaths %d", point.Ants, point.Turns, turns)
+				}
+				var used []resources.Path
+				for i, path := range paths {
+					if len(assignment[i]) > 0 {
+						used = append(used, path)
+					}
+				}
+				if !reflect.DeepEqual(point.Paths, used) {
+					t.Errorf("%d ants: Curve() uses %v, FindPaths %v", point.Ants, point.Paths, used)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathsReduced(t *testing.T) {
+	for _, name := range []string{"example00.txt", "example01.txt", "example02.txt", "example03.txt", "example04.txt", "big_chain.txt", "big_corridors.txt", "hyphens.txt"} {
+		t.Run(name, func(t *testing.T) {
+			colony, err := ParseFile(filepath.Join("..", "..", "testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ants := range []int{1, 2, 5, 10, 40} {
+				withAnts := *colony
+				withAnts.NumberOfAnts = ants
+				var stats Stats
+				paths, assignment, turns := FindPathsWith(&withAnts, SolveOptions{Stats: &stats, Reduce: true})
+				_, _, wholeTurns := FindPathsWith(&withAnts, SolveOptions{})
+				if turns > wholeTurns {
+					t.Errorf("%d ants: %d turns on the reduced graph, %d on the whole graph", ants, turns, wholeTurns)
+				}
+				if err := VerifyMoves(&withAnts, MoveAnts(paths, assignment, turns)); err != nil {
+					t.Errorf("%d ants: invalid moves: %v", ants, err)
+				}
+				if stats.ReducedRooms > stats.Rooms || stats.ReducedTunnels > stats.Tunnels {
+					t.Errorf("%d ants: graph grew from %d rooms, %d tunnels to %d, %d", ants, stats.Rooms, stats.Tunnels, stats.ReducedRooms, stats.ReducedTunnels)
+				}
+			}
+		})
+	}
+}
+
+func TestMinVertexCut(t *testing.T) {
+	tests := []struct {
+		name, input string
+		want        []string
+		ok          bool
+	}{
+		{"single corridor", "1\n##start\ns 0 0\na 1 0\nb 2 0\n##end\ne 3 0\ns-a\na-b\nb-e\n", []string{"a"}, true},
+		{"two routes", "1\n##start\ns 0 0\na 1 0\nb 1 1\n##end\ne 2 0\ns-a\ns-b\na-e\nb-e\n", []string{"a", "b"}, true},
+		{"choke after a fork", "1\n##start\ns 0 0\na 1 0\nb 1 1\nc 2 0\nd 3 0\n##end\ne 4 0\ns-a\ns-b\na-c\nb-c\nc-d\nd-e\n", []string{"c"}, true},
+		{"disconnected", "1\n##start\ns 0 0\na 1 0\n##end\ne 2 0\ns-a\n", []string{}, true},
+		{"direct link", "1\n##start\ns 0 0\na 1 0\n##end\ne 2 0\ns-a\na-e\ns-e\n", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			colony, err := Parse(strings.NewReader(test.input), ParseOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cut, ok := MinVertexCut(colony)
+			if !reflect.DeepEqual(cut, test.want) || ok != test.ok {
+				t.Errorf("MinVertexCut() = %v, %v, want %v, %v", cut, ok, test.want, test.ok)
+			}
+		})
+	}
+}
+
+func TestSolveStopsWithContext(t *testing.T) {
+	colony, err := ParseFile(filepath.Join("..", "..", "testdata", "big_corridors.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var stats Stats
+	paths, _, _ := FindPathsWith(colony, SolveOptions{Context: ctx, Stats: &stats})
+	if len(paths) != 0 || stats.Paths != 0 || stats.Augmentations != 0 {
+		t.Errorf("FindPathsWith() after cancel found %d paths, enumerated %d and augmented %d times", len(paths), stats.Paths, stats.Augmentations)
+	}
+	if _, err := SolveTimeExpandedContext(ctx, colony); !errors.Is(err, context.Canceled) {
+		t.Errorf("SolveTimeExpandedContext() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"time"
+
+	"lem-in/internal/resources"
+)
+
+// MoveAnts generates a slice of moves indicating the paths taken by each ant.
+// Each move is represented as a string "L<ant>-<room>".
+func MoveAnts(paths []resources.Path, antsPerRoom map[int][]int, totalTurns int) []string {
+	moves := make([]string, totalTurns)
+
+	var buf []byte
+	for turn := range moves {
+		buf = appendTurn(buf[:0], turn, paths, antsPerRoom)
+		moves[turn] = string(buf)
+	}
+
+	return moves
+}
+
+// WriteMoves streams the moves to w one turn per line, in the same format as MoveAnts.
+// Only a single turn is held in memory at a time, so very large ant counts can be
+// written without building the whole result first.
+func WriteMoves(w io.Writer, paths []resources.Path, antsPerRoom map[int][]int, totalTurns int) error {
+	return WriteMovesStats(w, paths, antsPerRoom, totalTurns, nil)
+}
+
+// WriteMovesStats is WriteMoves adding the time spent generating and writing
+// the moves to stats.Schedule and stats.Output. A nil stats is ignored.
+func WriteMovesStats(w io.Writer, paths []resources.Path, antsPerRoom map[int][]int, totalTurns int, stats *Stats) error {
+	bw := bufio.NewWriter(w)
+
+	var buf []byte
+	for turn := 0; turn < totalTurns; turn++ {
+		var start time.Time
+		if stats != nil {
+			start = time.Now()
+		}
+		buf = appendTurn(buf[:0], turn, paths, antsPerRoom)
+		buf = append(buf, '\n')
+		if stats != nil {
+			written := time.Now()
+			stats.Schedule += written.Sub(start)
+			start = written
+		}
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+		if stats != nil {
+			stats.Output += time.Since(start)
+		}
+	}
+
+	start := time.Now()
+	err := bw.Flush()
+	if stats != nil {
+		stats.Output += time.Since(start)
+	}
+	return err
+}
+
+// appendTurn appends the space separated moves made during the given turn to buf.
+func appendTurn(buf []byte, turn int, paths []resources.Path, antsPerRoom map[int][]int) []byte {
+	EachMove(turn, paths, antsPerRoom, func(ant int, room string) {
+		if len(buf) > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, 'L')
+		buf = strconv.AppendInt(buf, int64(ant), 10)
+		buf = append(buf, '-')
+		buf = append(buf, room...)
+	})
+	return buf
+}
+
+// EachMove calls move for every move made during the given turn. Ants are
+// listed by path and then by their order on that path, matching MoveAnts.
+func EachMove(turn int, paths []resources.Path, antsPerRoom map[int][]int, move func(ant int, room string)) {
+	for pathIndex, path := range paths {
+		rooms := path.RoomsInThePath[1:]
+		ants := antsPerRoom[pathIndex] // Ants assigned to this path
+
+		// The ant at antIndex is in rooms[turn-antIndex] during this turn
+		first := turn - len(rooms) + 1
+		if first < 0 {
+			first = 0
+		}
+		for antIndex := first; antIndex <= turn && antIndex < len(ants); antIndex++ {
+			move(ants[antIndex], rooms[turn-antIndex])
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Span is the position of a room name in a map's text: a 0-based line and the
+// byte offsets of the name within it. Quoted names include their quotes.
+type Span struct {
+	Line, Start, End int
+}
+
+// Contains reports whether the byte offset col of the given line is in the span, or right after it.
+func (s Span) Contains(line, col int) bool {
+	return s.Line == line && s.Start <= col && col <= s.End
+}
+
+// RefKind tells where a room name is referred to, which decides how a new name is written there.
+type RefKind int
+
+const (
+	RefLink       RefKind = iota // One end of a link line
+	RefAnnotation                // The room= field of a #@ annotation
+)
+
+// OutlineRoom is a room line of a map.
+type OutlineRoom struct {
+	Name string
+	X, Y int
+	Span Span // The name at the start of the room line
+}
+
+// Reference is a use of a room name outside its room line.
+type Reference struct {
+	Name string
+	Kind RefKind
+	Span Span
+}
+
+// Outline locates the room names of a map's text, for editors. Unlike Parse it
+// never fails: lines that do not parse are left out.
+type Outline struct {
+	Rooms []OutlineRoom
+	Refs  []Reference
+}
+
+// TextEdit replaces the text of a span.
+type TextEdit struct {
+	Span Span
+	Text string
+}
+
+// NewOutline locates the rooms, links and annotations in the text of a map.
+func NewOutline(text string) *Outline {
+	o := &Outline{}
+	lines := strings.Split(text, "\n")
+	exists := make(map[string]bool)
+	var links []int
+
+	for i, line := range lines {
+		line = strings.TrimSuffix(line, "\r")
+		lines[i] = line
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case isAnnotation(trimmed):
+			if ref, ok := annotationRef(line, i); ok {
+				o.Refs = append(o.Refs, ref)
+			}
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case isRoomLine(trimmed):
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				continue
+			}
+			x, errX := strconv.Atoi(fields[1])
+			y, errY := strconv.Atoi(fields[2])
+			if errX != nil || errY != nil {
+				continue
+			}
+			start := strings.Index(line, fields[0])
+			o.Rooms = append(o.Rooms, OutlineRoom{
+				Name: fields[0],
+				X:    x,
+				Y:    y,
+				Span: Span{Line: i, Start: start, End: start + len(fields[0])},
+			})
+			exists[fields[0]] = true
+		case isLinkLine(trimmed):
+			links = append(links, i) // Split once every room is known
+		}
+	}
+
+	isRoom := func(name string) bool { return exists[name] }
+	for _, i := range links {
+		line := lines[i]
+		offset := len(line) - len(strings.TrimLeft(line, " \t"))
+		from, to, fromSpan, toSpan, err := linkSpans(strings.TrimSpace(line), isRoom)
+		if err != nil {
+			continue
+		}
+		o.Refs = append(o.Refs,
+			Reference{Name: from, Kind: RefLink, Span: Span{Line: i, Start: offset + fromSpan[0], End: offset + fromSpan[1]}},
+			Reference{Name: to, Kind: RefLink, Span: Span{Line: i, Start: offset + toSpan[0], End: offset + toSpan[1]}},
+		)
+	}
+	return o
+}
+
+// linkSpans splits a link line like splitLinkFunc and also returns the byte
+// range of each name in the line.
+func linkSpans(line string, exists func(name string) bool) (string, string, [2]int, [2]int, error) {
+	if !strings.Contains(line, `"`) {
+		from, to, err := splitBareLink(line, exists)
+		return from, to, [2]int{0, len(from)}, [2]int{len(from) + 1, len(line)}, err
+	}
+
+	from, to, err := splitLinkFunc(line, exists)
+	if err != nil {
+		return "", "", [2]int{}, [2]int{}, err
+	}
+	_, rest, _ := readLinkName(line)
+	fromEnd := len(line) - len(rest)
+	return from, to, [2]int{0, fromEnd}, [2]int{fromEnd + 1, len(line)}, nil
+}
+
+// annotationRef locates the room= value of an annotation line.
+func annotationRef(line string, number int) (Reference, bool) {
+	room, _, err := parseAnnotation(strings.TrimSpace(line))
+	if err != nil {
+		return Reference{}, false
+	}
+	start := -1
+	for i := strings.Index(line, "room="); i >= 0; {
+		if i == 0 || line[i-1] == ' ' || line[i-1] == '@' {
+			start = i + len("room=")
+			break
+		}
+		next := strings.Index(line[i+1:], "room=")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	if start < 0 {
+		return Reference{}, false
+	}
+
+	end := strings.IndexByte(line[start:], ' ')
+	if strings.HasPrefix(line[start:], `"`) {
+		_, rest, _ := readQuoted(line[start:])
+		end = len(line[start:]) - len(rest)
+	}
+	if end < 0 {
+		end = len(line) - start
+	}
+	return Reference{Name: room, Kind: RefAnnotation, Span: Span{Line: number, Start: start, End: start + end}}, true
+}
+
+// Room returns the room line of the named room.
+func (o *Outline) Room(name string) (OutlineRoom, bool) {
+	for _, room := range o.Rooms {
+		if room.Name == name {
+			return room, true
+		}
+	}
+	return OutlineRoom{}, false
+}
+
+// NameAt returns the room name at a byte offset of a line, whether on its room line or in a reference.
+func (o *Outline) NameAt(line, col int) (string, bool) {
+	for _, room := range o.Rooms {
+		if room.Span.Contains(line, col) {
+			return room.Name, true
+		}
+	}
+	for _, ref := range o.Refs {
+		if ref.Span.Contains(line, col) {
+			return ref.Name, true
+		}
+	}
+	return "", false
+}
+
+// Degree returns the number of links of the named room.
+func (o *Outline) Degree(name string) int {
+	degree := 0
+	for _, ref := range o.Refs {
+		if ref.Kind == RefLink && ref.Name == name {
+			degree++
+		}
+	}
+	return degree
+}
+
+// Rename returns the edits renaming a room on its room line, in links and in
+// annotations. Names are quoted where the new name needs it.
+func (o *Outline) Rename(oldName, newName string) ([]TextEdit, error) {
+	if err := validateRoomName(newName); err != nil {
+		return nil, err
+	}
+	if _, exists := o.Room(newName); exists && newName != oldName {
+		return nil, fmt.Errorf("a room named %s already exists", newName)
+	}
+
+	var edits []TextEdit
+	for _, room := range o.Rooms {
+		if room.Name == oldName {
+			edits = append(edits, TextEdit{Span: room.Span, Text: newName})
+		}
+	}
+	for _, ref := range o.Refs {
+		if ref.Name != oldName {
+			continue
+		}
+		text := formatLinkName(newName)
+		if ref.Kind == RefAnnotation {
+			text = quoteValue(newName)
+		}
+		edits = append(edits, TextEdit{Span: ref.Span, Text: text})
+	}
+	return edits, nil
+}
@@ -0,0 +1,521 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"lem-in/internal/resources"
+)
+
+// ParseFile reads and validates an ant colony configuration file
+func ParseFile(filename string) (*resources.AntColony, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %v", err)
+	}
+	defer file.Close()
+
+	return Parse(file, ParseOptions{Filename: filename})
+}
+
+// Parse reads and validates an ant colony configuration, decompressing gzip
+// input. Unless opts.AllErrors is set it stops at the first problem; the error
+// is then a *ParseError, otherwise it joins one *ParseError per problem found.
+func Parse(r io.Reader, opts ParseOptions) (*resources.AntColony, error) {
+	p := &parser{opts: opts}
+	contents, source, err := p.readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if p.failed() {
+		return nil, p.err()
+	}
+
+	if len(contents) == 0 {
+		return nil, &ParseError{Err: errors.New("empty file")}
+	}
+
+	colony := &resources.AntColony{
+		Rooms:  make([]resources.Room, 0),
+		Links:  make(map[string][]string),
+		Source: source,
+	}
+
+	// Parse number of ants
+	antCount, err := strconv.Atoi(contents[0].text)
+	if err != nil {
+		err = errors.New("invalid number of ants")
+	} else if antCount <= 0 {
+		err = errors.New("number of ants must be positive")
+	}
+	if p.report(contents[0], err) {
+		return nil, p.err()
+	}
+	colony.NumberOfAnts = antCount
+
+	// Parse rooms and connections. Strict mode wants the links last in each
+	// file, so an included file may bring rooms after the links of another.
+	seenLink := make(map[*includeScope]bool)
+	for i := 1; i < len(contents); i++ {
+		line := contents[i].text
+		at := contents[i]
+
+		isLink := isLinkLine(line)
+		if opts.Mode == ModeStrict && seenLink[at.scope] && !isLink {
+			if p.report(at, fmt.Errorf("room or command after links: %s", line)) {
+				return nil, p.err()
+			}
+		}
+		seenLink[at.scope] = seenLink[at.scope] || isLink
+
+		switch {
+		case strings.Trim(line, " ") == "##start" || strings.Trim(line, " ") == "##end":
+			kind := strings.TrimPrefix(strings.Trim(line, " "), "##")
+			if i+1 >= len(contents) {
+				if p.report(at, fmt.Errorf("missing %s room definition", kind)) {
+					return nil, p.err()
+				}
+				continue
+			}
+			i++ // The next line holds the room
+			roomName, err := p.addRoom(contents[i].text, colony)
+			if err != nil {
+				if p.report(contents[i], fmt.Errorf("invalid %s room: %v", kind, err)) {
+					return nil, p.err()
+				}
+				continue
+			}
+			if kind == "start" {
+				colony.Start = roomName
+			} else {
+				colony.End = roomName
+			}
+
+		case strings.HasPrefix(line, "##") && !isBuiltinDirective(line):
+			next := ""
+			if i+1 < len(contents) {
+				next = contents[i+1].text
+			}
+			if p.report(at, p.directive(line, next, colony)) {
+				return nil, p.err()
+			}
+
+		case strings.Contains(line, " "):
+			if _, err := p.addRoom(line, colony); err != nil {
+				if p.report(at, fmt.Errorf("invalid room: %v", err)) {
+					return nil, p.err()
+				}
+			}
+
+		case strings.Contains(line, "-"):
+			if p.report(at, parseConnection(line, colony)) {
+				return nil, p.err()
+			}
+		default:
+			if p.report(at, fmt.Errorf("unrecognized command, room, or link: %s", line)) {
+				return nil, p.err()
+			}
+		}
+	}
+
+	if p.applyAnnotations(colony) {
+		return nil, p.err()
+	}
+
+	// Validate colony configuration
+	for _, err := range validateColony(colony) {
+		if p.report(sourceLine{}, err) {
+			return nil, p.err()
+		}
+	}
+	if p.failed() {
+		return nil, p.err()
+	}
+
+	return colony, nil
+}
+
+// sourceLine is a line kept by the parser with where it was read: its 1-based
+// number and, for included files, the file.
+type sourceLine struct {
+	text   string
+	number int
+	file   string
+	scope  *includeScope // The file as included, for checks made per file
+}
+
+// parser collects the problems found while parsing.
+type parser struct {
+	opts    ParseOptions
+	errors  []error
+	pending map[string]string // Custom directives waiting for the next room
+
+	annotations []sourceLine // #@ lines, applied once every room is known
+}
+
+// directive runs the handler of a custom ## command and keeps the command for the next room.
+func (p *parser) directive(line, next string, colony *resources.AntColony) error {
+	name, args := splitDirective(line)
+	handler, exists := p.opts.Directives.lookup(name)
+	if !exists {
+		if p.opts.ReportUnknownDirectives || p.opts.Mode == ModeStrict {
+			return fmt.Errorf("unknown command: %s", line)
+		}
+		return nil
+	}
+	if err := handler(args, next, colony); err != nil {
+		return fmt.Errorf("##%s: %v", name, err)
+	}
+	if p.pending == nil {
+		p.pending = make(map[string]string)
+	}
+	p.pending[name] = args
+	return nil
+}
+
+// report records err, if any, found at the given line and returns true when
+// parsing should stop.
+func (p *parser) report(at sourceLine, err error) bool {
+	if err == nil {
+		return false
+	}
+	parseErr := &ParseError{File: at.file, Line: at.number, Err: err}
+	if at.scope != nil {
+		parseErr.IncludeLine = at.scope.line
+	}
+	p.errors = append(p.errors, parseErr)
+	return !p.opts.AllErrors
+}
+
+func (p *parser) failed() bool {
+	return len(p.errors) > 0
+}
+
+// err returns the problems found, joined when there are several.
+func (p *parser) err() error {
+	if len(p.errors) == 1 {
+		return p.errors[0]
+	}
+	return errors.Join(p.errors...)
+}
+
+// addRoom parses a room line and registers the room under its name.
+func (p *parser) addRoom(line string, colony *resources.AntColony) (string, error) {
+	roomName, err := parseRoom(line, colony)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(roomName, "L") {
+		return "", fmt.Errorf("room name cannot start with 'L': %s", roomName)
+	}
+	if _, exists := colony.Links[roomName]; exists {
+		return "", fmt.Errorf("duplicate room name: %s", roomName)
+	}
+	colony.Links[roomName] = []string{}
+	if p.pending != nil {
+		colony.Rooms[len(colony.Rooms)-1].Directives = p.pending
+		p.pending = nil
+	}
+	return roomName, nil
+}
+
+// keepDirective reports whether a ## line other than start and end is passed on to the parser.
+func (p *parser) keepDirective(text string) bool {
+	if !strings.HasPrefix(text, "##") {
+		return false
+	}
+	name, _ := splitDirective(text)
+	return isRegistered(p.opts, name) || p.opts.ReportUnknownDirectives
+}
+
+// fileContents reads non-empty and non-comment lines from a file
+func fileContents(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %v", err)
+	}
+	defer file.Close()
+
+	p := &parser{opts: ParseOptions{Filename: filename}}
+	contents, _, err := p.readLines(file)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, len(contents))
+	for i, line := range contents {
+		lines[i] = line.text
+	}
+	return lines, nil
+}
+
+// readLines reads the kept lines of the input, with includes expanded, and
+// returns them along with the text echoed before the moves.
+func (p *parser) readLines(r io.Reader) ([]sourceLine, string, error) {
+	lines, err := p.readSource(r, mainScope(p.opts.Filename))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var echoed strings.Builder
+	for _, line := range lines {
+		echoed.WriteString(line.text)
+		echoed.WriteByte('\n')
+	}
+	return lines, echoed.String(), nil
+}
+
+// readSource reads non-empty and non-comment lines, normalised for the parse
+// mode and renamed for the scope they are read in.
+func (p *parser) readSource(r io.Reader, scope *includeScope) ([]sourceLine, error) {
+	var lines []sourceLine
+	var links []int // Indexes of the scope's own links, renamed once every room is known
+	rooms := make(map[string]bool)
+	linked := false // Whether the scope had a link yet
+	r, err := decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		at := sourceLine{number: number, file: scope.file, scope: scope}
+		text, err := normalizeLine(scanner.Text(), number, p.opts)
+		if err != nil {
+			if p.report(at, err) {
+				return nil, p.err()
+			}
+			continue
+		}
+		at.text = text
+
+		switch {
+		case isInclude(text):
+			if p.opts.Mode == ModeStrict && linked {
+				if p.report(at, fmt.Errorf("room or command after links: %s", text)) {
+					return nil, p.err()
+				}
+			}
+			included, err := p.include(at, scope)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range included {
+				if isRoomLine(line.text) {
+					rooms[strings.Fields(line.text)[0]] = true
+				}
+			}
+			lines = append(lines, included...)
+
+		case isAnnotation(text) && (p.opts.Mode == ModeStrict || isRoomAnnotation(text)):
+			if scope.renames() {
+				at.text = scope.renameAnnotation(text)
+			}
+			p.annotations = append(p.annotations, at)
+
+		case text != "" && (!strings.HasPrefix(text, "#") || isBuiltinDirective(text) || p.keepDirective(text)):
+			if scope.renames() {
+				switch {
+				case isRoomLine(text):
+					at.text = scope.renameRoom(text)
+					rooms[strings.Fields(at.text)[0]] = true
+				case isLinkLine(text):
+					links = append(links, len(lines))
+				}
+			}
+			linked = linked || isLinkLine(text)
+			lines = append(lines, at)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	for _, i := range links {
+		text, err := scope.renameLink(lines[i].text, rooms)
+		if err != nil {
+			if p.report(lines[i], err) {
+				return nil, p.err()
+			}
+			text = "" // Dropped below
+		}
+		lines[i].text = text
+	}
+	if len(links) > 0 {
+		kept := lines[:0]
+		for _, line := range lines {
+			if line.text != "" {
+				kept = append(kept, line)
+			}
+		}
+		lines = kept
+	}
+
+	return lines, nil
+}
+
+// decompress returns a reader of the decompressed input when r starts with
+// the gzip magic bytes, and of the input itself otherwise.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	if len(magic) < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %v", err)
+	}
+	return zr, nil
+}
+
+// include reads the file named by the ##include line at, returning nil lines
+// when the problem is reported and parsing goes on.
+func (p *parser) include(at sourceLine, scope *includeScope) ([]sourceLine, error) {
+	child, err := scope.include(strings.TrimSpace(strings.TrimPrefix(at.text, includeDirective)))
+	if err == nil {
+		if child.line == 0 {
+			child.line = at.number
+		}
+		var lines []sourceLine
+		if lines, err = p.readInclude(child); err == nil {
+			return lines, nil
+		}
+		if p.failed() && errors.As(err, new(*ParseError)) {
+			return nil, err // Stopped at a problem inside the included file
+		}
+	}
+	if p.report(at, err) {
+		return nil, p.err()
+	}
+	return nil, nil
+}
+
+// parseRoom parses a room definition line and adds it to the colony
+func parseRoom(line string, colony *resources.AntColony) (string, error) {
+	parts := strings.Fields(line)
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid room format")
+	}
+
+	name := parts[0]
+	if err := validateRoomName(name); err != nil {
+		return "", err
+	}
+
+	x, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid X coordinate: %v", err)
+	}
+
+	y, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid Y coordinate: %v", err)
+	}
+
+	// Check for duplicate coordinates, indexing rooms added without parseRoom first
+	if colony.Coords == nil || len(colony.Coords) != len(colony.Rooms) {
+		colony.Coords = make(map[[2]int]string, len(colony.Rooms))
+		for _, room := range colony.Rooms {
+			colony.Coords[[2]int{room.Coord_X, room.Coord_Y}] = room.Name
+		}
+	}
+	if _, exists := colony.Coords[[2]int{x, y}]; exists {
+		return "", errors.New("duplicate room coordinates")
+	}
+
+	room := resources.Room{
+		Name:    parts[0],
+		Coord_X: x,
+		Coord_Y: y,
+	}
+	colony.Rooms = append(colony.Rooms, room)
+	colony.Coords[[2]int{x, y}] = room.Name
+	return room.Name, nil
+}
+
+// parseConnection parses a room connection line and adds it to the colony
+func parseConnection(line string, colony *resources.AntColony) error {
+	from, to, err := splitLink(line, colony)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return errors.New("invalid room connection")
+	}
+
+	// Verify both rooms exist
+	if _, exists := colony.Links[from]; !exists {
+		return fmt.Errorf("room does not exist: %s", from)
+	}
+	if _, exists := colony.Links[to]; !exists {
+		return fmt.Errorf("room does not exist: %s", to)
+	}
+
+	// Check for duplicate tunnels, indexing tunnels added without parseConnection first
+	if colony.Linked == nil || len(colony.Linked) != 2*len(colony.Tunnels) {
+		colony.Linked = make(map[resources.Link]bool, 2*len(colony.Tunnels))
+		for _, tunnel := range colony.Tunnels {
+			colony.Linked[tunnel] = true
+			colony.Linked[resources.Link{From: tunnel.To, To: tunnel.From}] = true
+		}
+	}
+	link := resources.Link{From: from, To: to}
+	if colony.Linked[link] {
+		return fmt.Errorf("duplicate room connection: %s", FormatLink(from, to))
+	}
+
+	colony.Linked[link] = true
+	colony.Linked[resources.Link{From: to, To: from}] = true
+	colony.Tunnels = append(colony.Tunnels, link)
+
+	// Add bidirectional connection
+	colony.Links[from] = append(colony.Links[from], to)
+	colony.Links[to] = append(colony.Links[to], from)
+	return nil
+}
+
+// validateColony performs final validation of the colony configuration and
+// returns every problem found, in the order they are checked.
+func validateColony(colony *resources.AntColony) []error {
+	var errs []error
+	if colony.Start == "" {
+		errs = append(errs, errors.New("no start room found"))
+	}
+	if colony.End == "" {
+		errs = append(errs, errors.New("no end room found"))
+	}
+
+	// Verify start and end rooms exist in links
+	if _, exists := colony.Links[colony.Start]; colony.Start != "" && !exists {
+		errs = append(errs, errors.New("start room not found in connections"))
+	}
+	if _, exists := colony.Links[colony.End]; colony.End != "" && !exists {
+		errs = append(errs, errors.New("end room not found in connections"))
+	}
+
+	return errs
+}
+
+// validateRoomName checks if a room name is valid. Names may contain any
+// printable characters except whitespace, and cannot start with 'L' or '#'.
+func validateRoomName(name string) error {
+	if name == "" {
+		return errors.New("empty room name")
+	}
+	if name[0] == 'L' || name[0] == '#' {
+		return fmt.Errorf("invalid room name: %s", name)
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return fmt.Errorf("invalid room name: %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseMode selects how strictly the colony format is enforced. CRLF line
+// endings are accepted in every mode, since the scanner drops the \r.
+type ParseMode int
+
+const (
+	// ModeDefault accepts the format the way the original parser did.
+	ModeDefault ParseMode = iota
+	// ModeStrict also rejects unknown ## commands, tabs, trailing whitespace,
+	// rooms defined after the first link of a file and #@ lines that are not
+	// room annotations.
+	ModeStrict
+	// ModeLenient also accepts a UTF-8 byte order mark, tabs, surrounding
+	// whitespace and directives in any letter case.
+	ModeLenient
+)
+
+// ParseOptions configures Parse.
+type ParseOptions struct {
+	Mode ParseMode
+	// AllErrors keeps parsing after recoverable errors and reports every problem found.
+	AllErrors bool
+	// Directives handles custom ## commands.
+	Directives *DirectiveRegistry
+	// ReportUnknownDirectives reports ## commands that have no handler instead
+	// of skipping them as comments. Strict mode always reports them.
+	ReportUnknownDirectives bool
+	// Filename names the input. ##include paths are resolved relative to it,
+	// or to the working directory when it is empty.
+	Filename string
+}
+
+// ParseError is a problem found while parsing, with the line it was found on.
+type ParseError struct {
+	File        string // The included file the line is in, "" for the main input
+	Line        int    // 1-based, or 0 for problems with the colony as a whole
+	IncludeLine int    // The main input's ##include line that File comes from
+	Err         error
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line == 0:
+		return e.Err.Error()
+	case e.File != "":
+		return fmt.Sprintf("%s: line %d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+const byteOrderMark = "\uFEFF"
+
+// normalizeLine applies the mode's rules to a raw input line. number is the
+// 1-based line number.
+func normalizeLine(text string, number int, opts ParseOptions) (string, error) {
+	switch opts.Mode {
+	case ModeStrict:
+		if strings.Contains(text, "\t") {
+			return "", errors.New("tab character")
+		}
+		if strings.TrimRight(text, " ") != text {
+			return "", errors.New("trailing whitespace")
+		}
+		if strings.HasPrefix(text, "##") && text != "##start" && text != "##end" && !isInclude(text) {
+			if name, _ := splitDirective(text); !isRegistered(opts, name) {
+				return "", fmt.Errorf("unknown command: %s", text)
+			}
+		}
+
+	case ModeLenient:
+		if number == 1 {
+			text = strings.TrimPrefix(text, byteOrderMark)
+		}
+		text = strings.TrimSpace(strings.ReplaceAll(text, "\t", " "))
+		if strings.HasPrefix(text, "##") {
+			// Only the command name is case-insensitive, its arguments are kept as written
+			name, args, found := strings.Cut(text, " ")
+			text = strings.ToLower(name)
+			if found {
+				text += " " + args
+			}
+		}
+	}
+	return text, nil
+}
+
+func isRegistered(opts ParseOptions, name string) bool {
+	_, exists := opts.Directives.lookup(name)
+	return exists
+}
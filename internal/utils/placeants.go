@@ -0,0 +1,64 @@
+package utils
+
+import "lem-in/internal/resources"
+
+// PlaceAnts assigns ants to paths in the colony and returns a map of path indices to the ants assigned to them.
+func PlaceAnts(colony *resources.AntColony, paths []resources.Path) map[int][]int {
+	return placeAnts(colony.NumberOfAnts, namedLengths(paths))
+}
+
+// placeAnts is PlaceAnts on the number of rooms of each path, which is all the placement depends on.
+func placeAnts(totalAnts int, lengths []int) map[int][]int {
+	pathAssignments := make(map[int][]int)
+
+	for ant := 1; ant <= totalAnts; ant++ {
+		placeAnt(ant, lengths, pathAssignments)
+	}
+
+	return pathAssignments
+}
+
+// placeAnt attempts to place an ant on a path recursively, ensuring an optimal distribution of ants.
+func placeAnt(ant int, lengths []int, pathAssignments map[int][]int) bool {
+	return placeAntHelper(ant, lengths, pathAssignments, 0)
+}
+
+// placeAntHelper is a recursive helper function for placing ants optimally across paths.
+func placeAntHelper(ant int, lengths []int, pathAssignments map[int][]int, currentPath int) bool {
+	// Base case: Assign to the last path if it's the only choice
+	if currentPath == len(lengths)-1 {
+		pathAssignments[currentPath] = append(pathAssignments[currentPath], ant)
+		return true
+	}
+
+	// Calculate distribution cost (rooms + ants already assigned) for the current and next paths
+	currentPathLoad := lengths[currentPath] - 2 + len(pathAssignments[currentPath])
+	nextPathLoad := lengths[currentPath+1] - 2 + len(pathAssignments[currentPath+1])
+
+	// Assign to the less costly path
+	if currentPathLoad > nextPathLoad {
+		return placeAntHelper(ant, lengths, pathAssignments, currentPath+1)
+	}
+
+	// Assign ant to the current path
+	pathAssignments[currentPath] = append(pathAssignments[currentPath], ant)
+	return true
+}
+
+// namedLengths returns the number of rooms of each path.
+func namedLengths(paths []resources.Path) []int {
+	lengths := make([]int, len(paths))
+	for i, path := range paths {
+		lengths[i] = len(path.RoomsInThePath)
+	}
+	return lengths
+}
+
+// pathLengths returns the number of rooms of each path of room IDs.
+func pathLengths(paths [][]int) []int {
+	lengths := make([]int, len(paths))
+	for i, path := range paths {
+		lengths[i] = len(path)
+	}
+	return lengths
+}
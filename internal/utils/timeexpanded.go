@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"context"
+	"errors"
+
+	"lem-in/internal/resources"
+)
+
+const (
+	networkSource = 0 // colony.Start, which can hold any number of ants
+	networkSink   = 1 // colony.End, which can hold any number of ants
+)
+
+// SolveTimeExpanded returns the minimum number of turns needed to move every ant from
+// colony.Start to colony.End. Unlike FindPaths it is not limited to room-disjoint paths:
+// it builds a time-expanded network where each room other than start and end is a
+// capacity-1 node per turn and every tunnel can carry one ant per turn, then adds turns
+// until the maximum flow reaches the number of ants. The network grows with
+// rooms × turns, so it is meant for small and medium maps and as a reference for
+// grading the heuristic solvers.
+func SolveTimeExpanded(colony *resources.AntColony) (int, error) {
+	return SolveTimeExpandedContext(context.Background(), colony)
+}
+
+// SolveTimeExpandedContext is SolveTimeExpanded giving up with ctx.Err() once
+// ctx is done.
+func SolveTimeExpandedContext(ctx context.Context, colony *resources.AntColony) (int, error) {
+	if colony.NumberOfAnts <= 0 {
+		return 0, nil
+	}
+	graph := resources.NewGraph(colony)
+	if graph.Start < 0 || graph.End < 0 || !reachable(graph, graph.Start, graph.End) {
+		return 0, errors.New("no path from start to end room")
+	}
+	// Dead ends stay: unlike a path, a schedule may park an ant in one
+	connected := resources.Connected(graph)
+
+	network := &flowNetwork{}
+	network.addNode() // networkSource
+	network.addNode() // networkSink
+
+	var prevOut []int // out nodes of the previous turn's layer, by room
+	flow := 0
+	for turn := 1; ; turn++ {
+		in := make([]int, graph.Len())
+		out := make([]int, graph.Len())
+		for room := range in {
+			if room == graph.Start || room == graph.End || !connected[room] {
+				continue
+			}
+			in[room] = network.addNode()
+			out[room] = network.addNode()
+			network.addEdge(in[room], out[room], 1) // One ant per room per turn
+		}
+
+		// Ants leave the start room, one per tunnel per turn
+		for _, next := range graph.Neighbors(graph.Start) {
+			switch next {
+			case graph.End:
+				network.addEdge(networkSource, networkSink, 1)
+			case graph.Start:
+			default:
+				network.addEdge(networkSource, in[next], 1)
+			}
+		}
+
+		// Ants in the previous layer either wait or move through a tunnel
+		if prevOut != nil {
+			for room := range prevOut {
+				if room == graph.Start || room == graph.End || !connected[room] {
+					continue
+				}
+				network.addEdge(prevOut[room], in[room], 1)
+				for _, next := range graph.Neighbors(room) {
+					switch next {
+					case graph.End:
+						network.addEdge(prevOut[room], networkSink, 1)
+					case graph.Start:
+					default:
+						network.addEdge(prevOut[room], in[next], 1)
+					}
+				}
+			}
+		}
+		prevOut = out
+
+		for flow < colony.NumberOfAnts && network.augment(networkSource, networkSink) {
+			flow++
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if flow == colony.NumberOfAnts {
+			return turn, nil
+		}
+	}
+}
+
+// reachable reports whether the room to can be reached from the room from.
+func reachable(graph *resources.Graph, from, to int) bool {
+	seen := make([]bool, graph.Len())
+	seen[from] = true
+	queue := []int{from}
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+		if room == to {
+			return true
+		}
+		for _, next := range graph.Neighbors(room) {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"lem-in/internal/resources"
+)
+
+// SolveOptions configures FindPathsWith.
+type SolveOptions struct {
+	// Trace receives the solver's decisions at debug level.
+	Trace *slog.Logger
+	// Stats, when set, is filled with counters and phase timings.
+	Stats *Stats
+	// Reduce prunes dead ends and contracts chains of rooms before solving
+	// instead of solving the whole graph.
+	Reduce bool
+	// Context, when set, stops the search once it is done. FindPathsWith then
+	// returns no paths and the caller is expected to check Context.Err.
+	Context context.Context
+}
+
+// Stats counts the work done by the solver and the move writer and times each phase.
+type Stats struct {
+	QueuePeak      int           // Most partial paths waiting in the BFS queue at once
+	Paths          int           // Paths enumerated by the BFS
+	Augmentations  int           // Augmenting paths found for the disjoint path sets
+	Rooms          int           // Rooms of the map
+	Tunnels        int           // Tunnels of the map
+	ReducedRooms   int           // Rooms left once dead ends are pruned and chains contracted
+	ReducedTunnels int           // Tunnels left, each contracted chain counting as one
+	Reduce         time.Duration // Pruning and contracting the graph
+	Search         time.Duration // BFS and augmenting path search
+	Assign         time.Duration // Choosing a path set and placing the ants
+	Schedule       time.Duration // Generating the moves of each turn
+	Output         time.Duration // Writing the moves
+}
+
+// tracer logs the decisions of the solver at debug level, counts its work in
+// stats and tells the solver when ctx is done. The zero tracer does none of
+// these, so the solver only pays for tracing when it is enabled. The solver
+// works on room IDs, and graph names the rooms of the paths it logs.
+type tracer struct {
+	log   *slog.Logger
+	stats *Stats
+	ctx   context.Context
+	graph *resources.Graph
+}
+
+// stopped reports whether the solver should give up because ctx is done.
+func (t tracer) stopped() bool {
+	return t.ctx != nil && t.ctx.Err() != nil
+}
+
+func (t tracer) enabled() bool {
+	return t.log != nil && t.log.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// event logs msg with the given attributes when tracing is enabled.
+func (t tracer) event(msg string, attrs ...slog.Attr) {
+	if t.enabled() {
+		t.log.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+	}
+}
+
+// names returns the names of the rooms of a path, for logging.
+func (t tracer) names(path []int) []string {
+	return t.graph.PathNames(path).RoomsInThePath
+}
+
+// pathsAttr describes a path set as lists of room names.
+func (t tracer) pathsAttr(key string, paths [][]int) slog.Attr {
+	rooms := make([][]string, len(paths))
+	for i, path := range paths {
+		rooms[i] = t.names(path)
+	}
+	return slog.Any(key, rooms)
+}
+
+// placementAttr describes how many ants each path of a set carries.
+func placementAttr(paths [][]int, assignment map[int][]int) slog.Attr {
+	ants := make([]int, len(paths))
+	for i := range paths {
+		ants[i] = len(assignment[i])
+	}
+	return slog.Any("ants", ants)
+}
+
+// placeAndCount places the ants on a candidate path set, logging the result.
+func (t tracer) placeAndCount(set string, colony *resources.AntColony, paths [][]int) (map[int][]int, int) {
+	lengths := pathLengths(paths)
+	assignment := placeAnts(colony.NumberOfAnts, lengths)
+	turns := countTurns(assignment, lengths)
+	if t.enabled() {
+		t.event("placement", slog.String("set", set), t.pathsAttr("paths", paths), placementAttr(paths, assignment), slog.Int("turns", turns))
+	}
+	return assignment, turns
+}
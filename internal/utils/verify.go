@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lem-in/internal/resources"
+)
+
+// antMove is a single "L<ant>-<room>" move within a turn.
+type antMove struct {
+	ant      int
+	from, to string
+}
+
+// VerifyMoves checks that the given turns form a valid solution for the colony.
+// Every ant must start in colony.Start, move at most once per turn through an
+// existing tunnel, never share a room other than start and end with another ant,
+// never use a tunnel twice in the same turn, and finish in colony.End.
+func VerifyMoves(colony *resources.AntColony, moves []string) error {
+	position := make([]string, colony.NumberOfAnts+1)
+	for ant := range position {
+		position[ant] = colony.Start
+	}
+	occupied := make(map[string]int) // Intermediate rooms and the ant inside them
+
+	for turn, line := range moves {
+		var turnMoves []antMove
+		moved := make(map[int]bool)
+		tunnels := make(map[string]bool)
+
+		for _, token := range strings.Fields(line) {
+			ant, room, err := parseMove(token)
+			if err != nil {
+				return fmt.Errorf("turn %d: %v", turn+1, err)
+			}
+			if ant < 1 || ant > colony.NumberOfAnts {
+				return fmt.Errorf("turn %d: unknown ant: %d", turn+1, ant)
+			}
+			if moved[ant] {
+				return fmt.Errorf("turn %d: ant %d moved twice", turn+1, ant)
+			}
+			moved[ant] = true
+
+			from := position[ant]
+			if from == colony.End {
+				return fmt.Errorf("turn %d: ant %d moved after reaching the end room", turn+1, ant)
+			}
+			if !containsRoom(colony.Links[from], room) {
+				return fmt.Errorf("turn %d: no tunnel from %s to %s for ant %d", turn+1, from, room, ant)
+			}
+			tunnel := tunnelKey(from, room)
+			if tunnels[tunnel] {
+				return fmt.Errorf("turn %d: tunnel %s-%s used twice", turn+1, from, room)
+			}
+			tunnels[tunnel] = true
+
+			turnMoves = append(turnMoves, antMove{ant: ant, from: from, to: room})
+		}
+
+		// Ants leave their rooms before others may enter them
+		for _, m := range turnMoves {
+			if occupied[m.from] == m.ant {
+				delete(occupied, m.from)
+			}
+		}
+		for _, m := range turnMoves {
+			position[m.ant] = m.to
+			if m.to == colony.Start || m.to == colony.End {
+				continue
+			}
+			if other, exists := occupied[m.to]; exists {
+				return fmt.Errorf("turn %d: ants %d and %d are both in room %s", turn+1, other, m.ant, m.to)
+			}
+			occupied[m.to] = m.ant
+		}
+	}
+
+	for ant := 1; ant <= colony.NumberOfAnts; ant++ {
+		if position[ant] != colony.End {
+			return fmt.Errorf("ant %d did not reach the end room", ant)
+		}
+	}
+	return nil
+}
+
+// parseMove splits a move of the form "L<ant>-<room>".
+func parseMove(token string) (int, string, error) {
+	rest, found := strings.CutPrefix(token, "L")
+	if !found {
+		return 0, "", fmt.Errorf("invalid move: %s", token)
+	}
+	number, room, found := strings.Cut(rest, "-")
+	if !found || room == "" {
+		return 0, "", fmt.Errorf("invalid move: %s", token)
+	}
+	ant, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid move: %s", token)
+	}
+	return ant, room, nil
+}
+
+// tunnelKey identifies the tunnel between two rooms regardless of direction.
+func tunnelKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "\x00" + b
+}
@@ -0,0 +1,43 @@
+package lemin
+
+import "sort"
+
+// Itinerary is the journey of one ant through the colony.
+type Itinerary struct {
+	Ant    int    `json:"ant"`
+	Path   int    `json:"path"`   // Index in Solution.Paths of the path the ant takes
+	Depart int    `json:"depart"` // 1-based turn of the ant's first move
+	Stops  []Stop `json:"stops"`  // Rooms the ant enters after the start room, ending with the end room
+	Finish int    `json:"finish"` // 1-based turn at which the ant reaches the end room
+}
+
+// Stop is a room entered by an ant and the 1-based turn at which it enters it.
+type Stop struct {
+	Room string `json:"room"`
+	Turn int    `json:"turn"`
+}
+
+// Turns returns the number of turns the ant spends between the start and end
+// rooms, its first move included.
+func (it Itinerary) Turns() int {
+	return it.Finish - it.Depart + 1
+}
+
+// Itineraries returns the journey of every ant of the solution, by ant
+// number. Ants sent down a path leave one turn after another and move every
+// turn, as in Simulate.
+func Itineraries(s *Solution) []Itinerary {
+	var itineraries []Itinerary
+	for i, path := range s.Paths {
+		for order, ant := range s.Ants[i] {
+			it := Itinerary{Ant: ant, Path: i, Depart: order + 1, Stops: make([]Stop, len(path)-1)}
+			for step, room := range path[1:] {
+				it.Stops[step] = Stop{Room: room, Turn: order + step + 1}
+			}
+			it.Finish = it.Stops[len(it.Stops)-1].Turn
+			itineraries = append(itineraries, it)
+		}
+	}
+	sort.Slice(itineraries, func(i, j int) bool { return itineraries[i].Ant < itineraries[j].Ant })
+	return itineraries
+}
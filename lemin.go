@@ -0,0 +1,562 @@
+// Package lemin parses ant colony maps, finds how to move every ant from the
+// start room to the end room in as few turns as possible, and formats, simulates
+// and verifies the resulting moves.
+//
+// A typical caller parses a map, solves it and writes the standard output:
+//
+//	colony, err := lemin.ParseFile("map.txt", lemin.ParseOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	solution, err := lemin.Solve(colony, lemin.SolveOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	return lemin.Format(os.Stdout, colony, solution, lemin.FormatOptions{})
+//
+// The API follows semantic versioning as reported by Version: within a major
+// version, exported names keep their meaning and new fields are only added to
+// the options and result structs. The solver itself lives in internal packages
+// and may change between releases, so the exact paths chosen can differ as long
+// as the turn count does not get worse.
+package lemin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"lem-in/internal/resources"
+	"lem-in/internal/utils"
+)
+
+// Version is the version of the lemin API.
+const Version = "1.0.0"
+
+// ErrNoPath is returned by Solve when the end room cannot be reached from the start room.
+var ErrNoPath = errors.New("no path from start to end room")
+
+// Colony is a parsed ant colony map.
+type Colony struct {
+	Ants  int    `json:"ants"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Rooms []Room `json:"rooms"` // In declaration order
+	Links []Link `json:"links"` // In declaration order
+
+	// Source holds the map lines that are echoed before the moves: every
+	// non-empty line except comments, each followed by a newline.
+	Source string `json:"-"`
+}
+
+// Room is a room of the colony and its coordinates.
+type Room struct {
+	Name string `json:"name"`
+	X    int    `json:"x"`
+	Y    int    `json:"y"`
+	// Directives holds the arguments of the registered custom ## commands
+	// given before the room, by command name.
+	Directives map[string]string `json:"directives,omitempty"`
+	// Meta holds labels, tags, colours and other values from #@ annotations.
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// Link is a tunnel between two rooms.
+type Link struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Solution describes how the ants travel through the colony.
+type Solution struct {
+	Paths [][]string `json:"paths"` // Room names of each path, starting with Colony.Start
+	Ants  [][]int    `json:"ants"`  // Ants sent down each path, in the order they leave
+	Turns int        `json:"turns"` // Number of turns until the last ant arrives
+
+	// Optimum is the fewest turns any schedule can achieve, including ones
+	// where routes share rooms at different turns. It is only set when
+	// SolveOptions.Optimum is true.
+	Optimum int `json:"optimum,omitempty"`
+}
+
+// String returns the link as a link line, quoting room names where needed.
+func (l Link) String() string {
+	return utils.FormatLink(l.From, l.To)
+}
+
+// Move is one ant entering a room during a turn.
+type Move struct {
+	Ant  int
+	Room string
+}
+
+// String returns the move in the "L<ant>-<room>" output format.
+func (m Move) String() string {
+	return "L" + strconv.Itoa(m.Ant) + "-" + m.Room
+}
+
+// ParseMode selects how strictly Parse enforces the colony format. CRLF line
+// endings are accepted in every mode. Its values match the internal parser's modes.
+type ParseMode int
+
+const (
+	// ParseDefault accepts the format the way the lem-in command always has:
+	// empty lines and # comments are skipped and ##start/##end may be padded
+	// with spaces.
+	ParseDefault ParseMode = iota
+	// ParseStrict also rejects unknown ## commands, tabs, trailing whitespace
+	// and rooms or commands after the first link.
+	ParseStrict
+	// ParseLenient also accepts a UTF-8 byte order mark, tabs, leading and
+	// trailing whitespace and ##START/##End in any case.
+	ParseLenient
+)
+
+// ParseOptions configures Parse and ParseFile.
+type ParseOptions struct {
+	Mode ParseMode
+	// AllErrors keeps parsing past recoverable errors, such as a bad room or
+	// link line, and returns every problem found joined into one error.
+	AllErrors bool
+	// Directives handles custom ## commands such as ##capacity or ##hazard.
+	Directives *Directives
+	// ReportUnknownDirectives reports ## commands without a handler as errors
+	// instead of skipping them as comments. ParseStrict always reports them.
+	ReportUnknownDirectives bool
+	// Filename names the map being parsed. ##include paths are resolved
+	// relative to it, or to the working directory when it is empty. ParseFile
+	// sets it.
+	Filename string
+}
+
+// Directive is a custom ##<name> command found in a map.
+type Directive struct {
+	Name   string     // The command without the leading ##
+	Args   string     // The text after the name
+	Next   string     // The line after the command, usually the room it applies to
+	Colony ColonyView // The colony parsed before the command
+}
+
+// ColonyView is a read-only view of a colony while it is parsed. It shows the
+// lines read so far, so handlers should use it before they return.
+type ColonyView struct {
+	colony *resources.AntColony
+}
+
+// Ants returns the number of ants.
+func (v ColonyView) Ants() int {
+	return v.colony.NumberOfAnts
+}
+
+// Start returns the start room, "" until ##start and its room are read.
+func (v ColonyView) Start() string {
+	return v.colony.Start
+}
+
+// End returns the end room, "" until ##end and its room are read.
+func (v ColonyView) End() string {
+	return v.colony.End
+}
+
+// Rooms returns the rooms declared so far, in declaration order.
+func (v ColonyView) Rooms() []Room {
+	rooms := make([]Room, len(v.colony.Rooms))
+	for i, room := range v.colony.Rooms {
+		rooms[i] = viewRoom(room)
+	}
+	return rooms
+}
+
+// Room returns the named room if it is declared yet.
+func (v ColonyView) Room(name string) (Room, bool) {
+	for _, room := range v.colony.Rooms {
+		if room.Name == name {
+			return viewRoom(room), true
+		}
+	}
+	return Room{}, false
+}
+
+// viewRoom converts an internal room, copying its maps so the view stays read-only.
+func viewRoom(room resources.Room) Room {
+	return Room{Name: room.Name, X: room.Coord_X, Y: room.Coord_Y, Directives: maps.Clone(room.Directives), Meta: maps.Clone(room.Meta)}
+}
+
+// DirectiveHandler validates a custom command. A returned error is reported at the command's line.
+type DirectiveHandler func(d Directive) error
+
+// Directives is a registry of custom ## command handlers. Registered commands
+// are echoed with the map and attached to the next room in Room.Directives.
+type Directives struct {
+	registry *utils.DirectiveRegistry
+}
+
+// NewDirectives returns an empty registry.
+func NewDirectives() *Directives {
+	return &Directives{registry: utils.NewDirectiveRegistry()}
+}
+
+// Register adds the handler for ##<name>. ##start and ##end cannot be replaced.
+func (d *Directives) Register(name string, handler DirectiveHandler) error {
+	return d.registry.Register(name, func(args, next string, colony *resources.AntColony) error {
+		return handler(Directive{Name: name, Args: args, Next: next, Colony: ColonyView{colony}})
+	})
+}
+
+// ParseError is a problem found while parsing a map.
+type ParseError struct {
+	File string // The included file holding the line, "" for the map itself
+	Line int    // 1-based, or 0 for problems with the colony as a whole
+	// IncludeLine is the line of the map's ##include that brings in File,
+	// directly or through nested includes, and 0 when File is empty.
+	IncludeLine int
+	Err         error
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line == 0:
+		return e.Err.Error()
+	case e.File != "":
+		return fmt.Sprintf("%s: line %d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors lists the parse errors in an error returned by Parse, which
+// holds several of them when ParseOptions.AllErrors is set.
+func ParseErrors(err error) []*ParseError {
+	var errs []*ParseError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, ParseErrors(e)...)
+		}
+		return errs
+	}
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		errs = append(errs, parseErr)
+	}
+	return errs
+}
+
+// SolveOptions configures Solve.
+type SolveOptions struct {
+	// Optimum also computes Solution.Optimum with the exact time-expanded
+	// solver. Its cost grows with rooms × turns, so it suits small and medium maps.
+	Optimum bool
+	// Trace, when set, receives the solver's decisions as debug level records:
+	// the paths found, each candidate path set with its ant placement and
+	// turns, the paths discarded or replaced and the set chosen.
+	Trace *slog.Logger
+	// Stats, when set, has the solver's counters and timings added to it.
+	Stats *Stats
+	// Reduce prunes rooms that cannot be on a path from start to end and
+	// searches chains of rooms with two tunnels as single tunnels first. It is
+	// off by default because it can break ties between paths of the same
+	// length differently, which changes which ants take which path.
+	Reduce bool
+	// Context, when set, makes Solve stop and return Context.Err() once it is
+	// done, so a timeout or cancellation ends the search itself.
+	Context context.Context
+}
+
+// Stats counts the work done by Solve and Format and times their phases.
+// Counters and durations accumulate over the calls given the same Stats; the
+// graph sizes are those of the last map solved.
+type Stats struct {
+	QueuePeak      int           // Most partial paths queued at once by the breadth-first search
+	Paths          int           // Paths enumerated by the breadth-first search
+	Augmentations  int           // Augmenting paths found while building disjoint path sets
+	Rooms          int           // Rooms of the map
+	Tunnels        int           // Tunnels of the map
+	ReducedRooms   int           // Rooms searched once dead ends are pruned and chains contracted
+	ReducedTunnels int           // Tunnels searched, each contracted chain counting as one
+	Reduce         time.Duration // Pruning dead ends and contracting chains
+	Search         time.Duration // Finding paths
+	Assign         time.Duration // Choosing a path set and placing the ants
+	Schedule       time.Duration // Generating the moves of each turn
+	Output         time.Duration // Writing the map and the moves
+}
+
+// add accumulates the internal statistics.
+func (s *Stats) add(stats utils.Stats) {
+	s.QueuePeak = max(s.QueuePeak, stats.QueuePeak)
+	s.Paths += stats.Paths
+	s.Augmentations += stats.Augmentations
+	if stats.Rooms > 0 {
+		s.Rooms, s.Tunnels = stats.Rooms, stats.Tunnels
+		s.ReducedRooms, s.ReducedTunnels = stats.ReducedRooms, stats.ReducedTunnels
+	}
+	s.Reduce += stats.Reduce
+	s.Search += stats.Search
+	s.Assign += stats.Assign
+	s.Schedule += stats.Schedule
+	s.Output += stats.Output
+}
+
+// FormatOptions configures Format.
+type FormatOptions struct {
+	// MovesOnly leaves out the echoed map and the blank line after it.
+	MovesOnly bool
+	// NoEcho leaves out the echoed map but keeps the blank line, so the moves
+	// still start after the first empty line of the output.
+	NoEcho bool
+	// Stats, when set, has the time spent generating and writing moves added to it.
+	Stats *Stats
+}
+
+// Parse reads and validates a colony map. Gzip compressed input is
+// recognised by its magic bytes and decompressed.
+func Parse(r io.Reader, opts ParseOptions) (*Colony, error) {
+	internalOpts := utils.ParseOptions{
+		Mode:                    utils.ParseMode(opts.Mode),
+		AllErrors:               opts.AllErrors,
+		ReportUnknownDirectives: opts.ReportUnknownDirectives,
+		Filename:                opts.Filename,
+	}
+	if opts.Directives != nil {
+		internalOpts.Directives = opts.Directives.registry
+	}
+	colony, err := utils.Parse(r, internalOpts)
+	if err != nil {
+		return nil, publicError(err)
+	}
+	return fromAntColony(colony), nil
+}
+
+// ParseFile reads and validates the colony map in the named file.
+func ParseFile(filename string, opts ParseOptions) (*Colony, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	opts.Filename = filename
+	return Parse(file, opts)
+}
+
+// Solve chooses the paths and the ants sent down each of them.
+func Solve(c *Colony, opts SolveOptions) (*Solution, error) {
+	colony := c.antColony()
+	var stats utils.Stats
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	paths, antsPerPath, turns := utils.FindPathsWith(colony, utils.SolveOptions{Trace: opts.Trace, Stats: &stats, Reduce: opts.Reduce, Context: ctx})
+	if opts.Stats != nil {
+		opts.Stats.add(stats)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, ErrNoPath
+	}
+
+	solution := &Solution{Turns: turns}
+	for i, path := range paths {
+		solution.Paths = append(solution.Paths, path.RoomsInThePath)
+		solution.Ants = append(solution.Ants, antsPerPath[i])
+	}
+	if opts.Optimum {
+		optimum, err := utils.SolveTimeExpandedContext(ctx, colony)
+		if err != nil {
+			return nil, err
+		}
+		solution.Optimum = optimum
+	}
+	return solution, nil
+}
+
+// Simulate returns the moves made during each turn of the solution, in the
+// order Format writes them.
+func Simulate(s *Solution) [][]Move {
+	paths, antsPerPath := s.schedule()
+	turns := make([][]Move, s.Turns)
+	for turn := range turns {
+		utils.EachMove(turn, paths, antsPerPath, func(ant int, room string) {
+			turns[turn] = append(turns[turn], Move{Ant: ant, Room: room})
+		})
+	}
+	return turns
+}
+
+// schedule converts the solution to the paths and ant assignment the move
+// generator works on.
+func (s *Solution) schedule() ([]resources.Path, map[int][]int) {
+	paths := make([]resources.Path, len(s.Paths))
+	antsPerPath := make(map[int][]int, len(s.Ants))
+	for i, path := range s.Paths {
+		paths[i] = resources.Path{RoomsInThePath: path}
+		antsPerPath[i] = s.Ants[i]
+	}
+	return paths, antsPerPath
+}
+
+// Verify checks that the moves take every ant from the start room to the end
+// room without breaking the rules: one ant per room other than start and end,
+// one move per ant per turn and one ant per tunnel per turn.
+func Verify(c *Colony, turns [][]Move) error {
+	lines := make([]string, len(turns))
+	for i, moves := range turns {
+		words := make([]string, len(moves))
+		for j, move := range moves {
+			words[j] = move.String()
+		}
+		lines[i] = strings.Join(words, " ")
+	}
+	return utils.VerifyMoves(c.antColony(), lines)
+}
+
+// Format writes the solution in the standard output format: the echoed map, a
+// blank line, then one line of space separated moves per turn. Moves are
+// generated one turn at a time, so large solutions are streamed.
+func Format(w io.Writer, c *Colony, s *Solution, opts FormatOptions) error {
+	var stats utils.Stats
+	if opts.Stats != nil {
+		defer func(start time.Time) {
+			opts.Stats.add(stats)
+			// Echoing the map counts as output
+			opts.Stats.Output += time.Since(start) - stats.Schedule - stats.Output
+		}(time.Now())
+	}
+
+	switch {
+	case opts.MovesOnly:
+	case opts.NoEcho:
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	default:
+		if _, err := io.WriteString(w, c.Source+"\n"); err != nil {
+			return err
+		}
+	}
+
+	paths, antsPerPath := s.schedule()
+	return utils.WriteMovesStats(w, paths, antsPerPath, s.Turns, &stats)
+}
+
+// FormatMap writes the colony back as a map file. Rooms and links keep their
+// order, registered ## commands precede their rooms, room metadata is written
+// as #@ annotations after each room and room names are quoted in links where
+// needed, so parsing the result gives the same colony.
+func FormatMap(w io.Writer, c *Colony) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, c.Ants)
+	for _, room := range c.Rooms {
+		names := make([]string, 0, len(room.Directives))
+		for name := range room.Directives {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(bw, strings.TrimSpace("##"+name+" "+room.Directives[name]))
+		}
+
+		switch room.Name {
+		case c.Start:
+			fmt.Fprintln(bw, "##start")
+		case c.End:
+			fmt.Fprintln(bw, "##end")
+		}
+		fmt.Fprintln(bw, room.Name, room.X, room.Y)
+		if len(room.Meta) > 0 {
+			fmt.Fprintln(bw, utils.FormatAnnotation(room.Name, room.Meta))
+		}
+	}
+	for _, link := range c.Links {
+		fmt.Fprintln(bw, utils.FormatLink(link.From, link.To))
+	}
+	return bw.Flush()
+}
+
+// ParseLink reads a link line such as a-b or "north-gate"-hall between two
+// rooms of the colony.
+func ParseLink(c *Colony, line string) (Link, error) {
+	rooms := make(map[string]bool, len(c.Rooms))
+	for _, room := range c.Rooms {
+		rooms[room.Name] = true
+	}
+	from, to, err := utils.SplitLinkLine(strings.TrimSpace(line), func(name string) bool { return rooms[name] })
+	if err != nil {
+		return Link{}, err
+	}
+	for _, name := range []string{from, to} {
+		if !rooms[name] {
+			return Link{}, fmt.Errorf("room does not exist: %s", name)
+		}
+	}
+	if from == to {
+		return Link{}, errors.New("invalid room connection")
+	}
+	return Link{From: from, To: to}, nil
+}
+
+// publicError replaces the internal parse errors in err with ParseError values.
+func publicError(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			errs = append(errs, publicError(e))
+		}
+		return errors.Join(errs...)
+	}
+	var parseErr *utils.ParseError
+	if errors.As(err, &parseErr) {
+		return &ParseError{File: parseErr.File, Line: parseErr.Line, IncludeLine: parseErr.IncludeLine, Err: parseErr.Err}
+	}
+	return err
+}
+
+// fromAntColony converts the internal colony.
+func fromAntColony(colony *resources.AntColony) *Colony {
+	c := &Colony{
+		Ants:   colony.NumberOfAnts,
+		Start:  colony.Start,
+		End:    colony.End,
+		Source: colony.Source,
+	}
+	for _, room := range colony.Rooms {
+		c.Rooms = append(c.Rooms, Room{Name: room.Name, X: room.Coord_X, Y: room.Coord_Y, Directives: room.Directives, Meta: room.Meta})
+	}
+	for _, link := range colony.Tunnels {
+		c.Links = append(c.Links, Link{From: link.From, To: link.To})
+	}
+	return c
+}
+
+// antColony builds the internal colony used by the solver.
+func (c *Colony) antColony() *resources.AntColony {
+	colony := &resources.AntColony{
+		NumberOfAnts: c.Ants,
+		Start:        c.Start,
+		End:          c.End,
+		Links:        make(map[string][]string, len(c.Rooms)),
+	}
+	for _, room := range c.Rooms {
+		colony.Rooms = append(colony.Rooms, resources.Room{Name: room.Name, Coord_X: room.X, Coord_Y: room.Y, Directives: room.Directives, Meta: room.Meta})
+		colony.Links[room.Name] = []string{}
+	}
+	for _, link := range c.Links {
+		colony.Links[link.From] = append(colony.Links[link.From], link.To)
+		colony.Links[link.To] = append(colony.Links[link.To], link.From)
+	}
+	return colony
+}
@@ -0,0 +1,528 @@
+package lemin_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"lem-in"
+)
+
+func TestParseSolveVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTurns int
+		wantErr   error
+	}{
+		{
+			name:      "Two paths",
+			input:     "4\n##start\nstart 0 0\nroom1 1 0\nroom2 2 0\nroom3 3 0\n##end\nend 4 0\nstart-room1\nstart-room2\nroom1-room3\nroom2-end\nroom3-end\n",
+			wantTurns: 4,
+		},
+		{
+			name:      "Hyphenated rooms",
+			input:     "2\n##start\nnorth-gate 0 0\n##end\nsouth-gate 1 0\n\"north-gate\"-\"south-gate\"\n",
+			wantTurns: 2,
+		},
+		{
+			name:    "No path",
+			input:   "1\n##start\nstart 0 0\nroom 1 0\n##end\nend 2 0\nstart-room\n",
+			wantErr: lemin.ErrNoPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			colony, err := lemin.Parse(strings.NewReader(tt.input), lemin.ParseOptions{})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if colony.Source != tt.input {
+				t.Errorf("Parse() Source = %q, want %q", colony.Source, tt.input)
+			}
+
+			solution, err := lemin.Solve(colony, lemin.SolveOptions{Optimum: true})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Solve() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if solution.Turns != tt.wantTurns {
+				t.Errorf("Solve() Turns = %d, want %d", solution.Turns, tt.wantTurns)
+			}
+			if solution.Optimum > solution.Turns {
+				t.Errorf("Solve() Optimum = %d, more than Turns %d", solution.Optimum, solution.Turns)
+			}
+
+			turns := lemin.Simulate(solution)
+			if err := lemin.Verify(colony, turns); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+
+			// Format writes the same moves as Simulate
+			var out strings.Builder
+			if err := lemin.Format(&out, colony, solution, lemin.FormatOptions{MovesOnly: true}); err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			var want strings.Builder
+			for _, moves := range turns {
+				for i, move := range moves {
+					if i > 0 {
+						want.WriteString(" ")
+					}
+					want.WriteString(move.String())
+				}
+				want.WriteString("\n")
+			}
+			if out.String() != want.String() {
+				t.Errorf("Format() = %q, want %q", out.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsCollision(t *testing.T) {
+	colony, err := lemin.Parse(strings.NewReader("2\n##start\ns 0 0\na 1 0\n##end\ne 2 0\ns-a\na-e\n"), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	turns := [][]lemin.Move{
+		{{Ant: 1, Room: "a"}, {Ant: 2, Room: "a"}},
+		{{Ant: 1, Room: "e"}, {Ant: 2, Room: "e"}},
+	}
+	if err := lemin.Verify(colony, turns); err == nil {
+		t.Error("Verify() accepted two ants in one room")
+	}
+}
+
+func Example() {
+	colony, err := lemin.Parse(strings.NewReader("2\n##start\nstart 0 0\nmid 1 0\n##end\nend 2 0\nstart-mid\nmid-end\n"), lemin.ParseOptions{})
+	if err != nil {
+		panic(err)
+	}
+	solution, err := lemin.Solve(colony, lemin.SolveOptions{})
+	if err != nil {
+		panic(err)
+	}
+	lemin.Format(os.Stdout, colony, solution, lemin.FormatOptions{})
+	// Output:
+	// 2
+	// ##start
+	// start 0 0
+	// mid 1 0
+	// ##end
+	// end 2 0
+	// start-mid
+	// mid-end
+	//
+	// L1-mid
+	// L1-end L2-mid
+	// L2-end
+}
+
+func TestParseErrors(t *testing.T) {
+	input := "0\n##start\ns 0 0\n##end\ne 0 0\ns-x\n"
+	_, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{AllErrors: true})
+	errs := lemin.ParseErrors(err)
+	if len(errs) != 4 {
+		t.Fatalf("ParseErrors() = %v, want 4 errors", errs)
+	}
+	wantLines := []int{1, 5, 6, 0} // The missing end room is not tied to a line
+	for i, parseErr := range errs {
+		if parseErr.Line != wantLines[i] {
+			t.Errorf("error %d on line %d, want %d: %v", i, parseErr.Line, wantLines[i], parseErr)
+		}
+	}
+
+	_, err = lemin.Parse(strings.NewReader(input), lemin.ParseOptions{})
+	if errs := lemin.ParseErrors(err); len(errs) != 1 || errs[0].Line != 1 {
+		t.Errorf("ParseErrors() without AllErrors = %v, want the first error", errs)
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	directives := lemin.NewDirectives()
+	var seen []lemin.Directive
+	err := directives.Register("hazard", func(d lemin.Directive) error {
+		seen = append(seen, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	input := "1\n##start\ns 0 0\n##hazard lava\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n"
+	colony, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{Directives: directives})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(seen) != 1 || seen[0].Name != "hazard" || seen[0].Args != "lava" || seen[0].Next != "mid 1 0" {
+		t.Errorf("handler saw %v, want hazard lava before mid 1 0", seen)
+	}
+	if got := colony.Rooms[1].Directives["hazard"]; got != "lava" {
+		t.Errorf("room %s hazard = %q, want lava", colony.Rooms[1].Name, got)
+	}
+}
+
+func TestDirectiveColonyView(t *testing.T) {
+	directives := lemin.NewDirectives()
+	var starts []string
+	var rooms []int
+	err := directives.Register("near", func(d lemin.Directive) error {
+		starts = append(starts, d.Colony.Start())
+		rooms = append(rooms, len(d.Colony.Rooms()))
+		if _, ok := d.Colony.Room(d.Args); !ok {
+			return errors.New("no room " + d.Args + " yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	input := "3\n##start\ns 0 0\n##near s\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n"
+	if _, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{Directives: directives}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(starts, []string{"s"}) || !reflect.DeepEqual(rooms, []int{1}) {
+		t.Errorf("handler saw start %v and %v rooms, want [s] and [1]", starts, rooms)
+	}
+
+	input = "3\n##start\ns 0 0\n##near e\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n"
+	_, err = lemin.Parse(strings.NewReader(input), lemin.ParseOptions{Directives: directives})
+	if err == nil || !strings.Contains(err.Error(), "line 4: ##near: no room e yet") {
+		t.Errorf("Parse() error = %v, want no room e yet at line 4", err)
+	}
+}
+
+func TestFormatMapAndExports(t *testing.T) {
+	input := "2\n##start\ns 0 0\n#@ room=mid label=\"Main gate\" color=orange\nmid 1 0\n##end\ne 2 0\ns-mid\nmid-e\n"
+	colony, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var formatted strings.Builder
+	if err := lemin.FormatMap(&formatted, colony); err != nil {
+		t.Fatalf("FormatMap() error = %v", err)
+	}
+	again, err := lemin.Parse(strings.NewReader(formatted.String()), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse(FormatMap()) error = %v\n%s", err, formatted.String())
+	}
+	if got := again.Rooms[1].Meta["label"]; got != "Main gate" {
+		t.Errorf("label after round trip = %q, want Main gate", got)
+	}
+
+	solution, err := lemin.Solve(colony, lemin.SolveOptions{})
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	opts := lemin.ExportOptions{Solution: solution}
+	exports := []struct {
+		name   string
+		export func(io.Writer, *lemin.Colony, lemin.ExportOptions) error
+		want   []string
+	}{
+		{"JSON", lemin.ExportJSON, []string{`"label": "Main gate"`, `"solution"`}},
+		{"DOT", lemin.ExportDOT, []string{`"label"="Main gate"`, `"color"="orange"`, "penwidth=3"}},
+		{"SVG", lemin.ExportSVG, []string{`data-label="Main gate"`, `fill="orange"`, ">Main gate</text>"}},
+	}
+	for _, e := range exports {
+		var out strings.Builder
+		if err := e.export(&out, colony, opts); err != nil {
+			t.Fatalf("Export%s() error = %v", e.name, err)
+		}
+		for _, want := range e.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Export%s() missing %s:\n%s", e.name, want, out.String())
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	input := "3\n##start\ns 0 0\na 1 0\nb 1 1\nc 2 0\n##end\ne 3 0\ns-a\ns-b\na-c\nb-c\nc-e\n"
+	colony, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	solution, err := lemin.Solve(colony, lemin.SolveOptions{})
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+
+	analysis := lemin.Analyze(colony, solution)
+	if !reflect.DeepEqual(analysis.Cut, []string{"c"}) || analysis.DirectLink {
+		t.Errorf("Analyze() cut = %v, direct link %v, want [c] and false", analysis.Cut, analysis.DirectLink)
+	}
+	c := analysis.Rooms[2]
+	if c.Name != "c" || c.Ants != 3 || !reflect.DeepEqual(c.Turns, []int{2, 3, 4}) || !c.Bottleneck {
+		t.Errorf("Analyze() usage of c = %+v, want 3 ants at turns 2, 3 and 4", c)
+	}
+
+	opts := lemin.ExportOptions{Solution: solution, Highlight: analysis.Cut}
+	var dot, svg strings.Builder
+	if err := lemin.ExportDOT(&dot, colony, opts); err != nil || !strings.Contains(dot.String(), `"c" ["color"="#ff8c00", "penwidth"="3"`) {
+		t.Errorf("ExportDOT() does not highlight c: %v\n%s", err, dot.String())
+	}
+	if err := lemin.ExportSVG(&svg, colony, opts); err != nil || !strings.Contains(svg.String(), `<g class="room highlight" data-name="c">`) {
+		t.Errorf("ExportSVG() does not highlight c: %v\n%s", err, svg.String())
+	}
+
+	direct, err := lemin.Parse(strings.NewReader("1\n##start\ns 0 0\na 1 0\n##end\ne 2 0\ns-a\na-e\ns-e\n"), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if analysis := lemin.Analyze(direct, nil); len(analysis.Cut) != 0 || !analysis.DirectLink || analysis.Rooms != nil {
+		t.Errorf("Analyze() of a direct link = %+v", analysis)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	input := "10\n##start\ns 0 0\na 1 0\nx 1 1\n##end\ne 2 0\ns-a\na-e\n"
+	colony, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// A second route needs both tunnels, so neither helps on its own
+	candidates := []lemin.Link{{From: "s", To: "x"}, {From: "x", To: "e"}, {From: "a", To: "x"}}
+	suggestions, err := lemin.Suggest(colony, lemin.SuggestOptions{Candidates: candidates, Max: 2})
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	if suggestions.Turns != 11 || len(suggestions.Tunnels) != 3 || suggestions.Tunnels[0].Saved != 0 {
+		t.Errorf("Suggest() = %+v, want 11 turns and no single tunnel saving any", suggestions)
+	}
+	want := lemin.Suggestion{Links: candidates[:2], Turns: 6, Saved: 5}
+	if !reflect.DeepEqual(suggestions.Best, want) || !suggestions.Exhaustive {
+		t.Errorf("Suggest() best = %+v, want %+v", suggestions.Best, want)
+	}
+
+	// With more candidates than can be tried together, the greedy search still
+	// grows the best single tunnel, which saves nothing on its own
+	var many strings.Builder
+	many.WriteString(input)
+	crowded := append([]lemin.Link{}, candidates[:2]...)
+	for i := range 100 {
+		fmt.Fprintf(&many, "r%d %d 5\n", i, i)
+		crowded = append(crowded, lemin.Link{From: "a", To: fmt.Sprintf("r%d", i)})
+	}
+	crowdedColony, err := lemin.Parse(strings.NewReader(many.String()), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	suggestions, err = lemin.Suggest(crowdedColony, lemin.SuggestOptions{Candidates: crowded, Max: 2})
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	if !reflect.DeepEqual(suggestions.Best, want) || suggestions.Exhaustive {
+		t.Errorf("Suggest() best = %+v, exhaustive %v, want %+v built greedily", suggestions.Best, suggestions.Exhaustive, want)
+	}
+
+	// Within a distance of 1, x can only be joined to a
+	suggestions, err = lemin.Suggest(colony, lemin.SuggestOptions{Distance: 1})
+	if err != nil {
+		t.Fatalf("Suggest() error = %v", err)
+	}
+	if len(suggestions.Tunnels) != 1 || suggestions.Tunnels[0].Links[0] != (lemin.Link{From: "a", To: "x"}) {
+		t.Errorf("Suggest() with distance 1 tried %+v, want only a-x", suggestions.Tunnels)
+	}
+
+	if _, err := lemin.Suggest(colony, lemin.SuggestOptions{}); err == nil {
+		t.Error("Suggest() without candidates succeeded")
+	}
+	for _, link := range []lemin.Link{{From: "s", To: "a"}, {From: "s", To: "nowhere"}, {From: "x", To: "x"}} {
+		if _, err := lemin.Suggest(colony, lemin.SuggestOptions{Candidates: []lemin.Link{link}}); !errors.Is(err, lemin.ErrInvalidCandidate) {
+			t.Errorf("Suggest() with candidate %v error = %v, want ErrInvalidCandidate", link, err)
+		}
+	}
+}
+
+// spurMap sends its ants along s-a-e, past a room x off a, with a coloured by
+// an annotation.
+const spurMap = "3\n##start\ns 0 0\n#@ room=a color=#123456\na 1 0\nx 1 1\n##end\ne 2 0\ns-a\na-e\na-x\n"
+
+// solvedColony parses and solves a map, failing the test on any error.
+func solvedColony(t *testing.T, input string) (*lemin.Colony, *lemin.Solution) {
+	t.Helper()
+	colony, err := lemin.Parse(strings.NewReader(input), lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	solution, err := lemin.Solve(colony, lemin.SolveOptions{})
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	return colony, solution
+}
+
+func TestRender(t *testing.T) {
+	colony, solution := solvedColony(t, spurMap)
+
+	var buf bytes.Buffer
+	if err := lemin.WritePNG(&buf, colony, lemin.RenderOptions{Solution: solution}); err != nil {
+		t.Fatalf("WritePNG() error = %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	// One unit is 60 pixels and the margin 40, so s, a and e are at y 40 and x at 100, 100
+	if got := img.Bounds(); got != image.Rect(0, 0, 200, 140) {
+		t.Errorf("bounds = %v, want 200×140", got)
+	}
+	pixels := []struct {
+		name string
+		x, y int
+		want color.RGBA
+	}{
+		{"start room", 40, 40, color.RGBA{0x8f, 0xd1, 0x8f, 0xff}},
+		{"end room", 160, 40, color.RGBA{0xf0, 0x80, 0x80, 0xff}},
+		{"room colour metadata", 100, 40, color.RGBA{0x12, 0x34, 0x56, 0xff}},
+		{"path tunnel", 70, 40, color.RGBA{0xe6, 0x19, 0x4b, 0xff}},
+		{"unused tunnel", 100, 70, color.RGBA{0x99, 0x99, 0x99, 0xff}},
+		{"unused room", 100, 100, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+		{"background", 5, 5, color.RGBA{0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, p := range pixels {
+		if got := color.RGBAModel.Convert(img.At(p.x, p.y)); got != p.want {
+			t.Errorf("%s at %d, %d = %v, want %v", p.name, p.x, p.y, got, p.want)
+		}
+	}
+
+	hidden := lemin.RenderImage(colony, lemin.RenderOptions{Solution: solution, HideUnused: true})
+	if got := hidden.Bounds(); got != image.Rect(0, 0, 200, 80) {
+		t.Errorf("bounds without unused rooms = %v, want 200×80", got)
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	colony, solution := solvedColony(t, spurMap)
+	if err := lemin.WriteGIF(io.Discard, colony, lemin.AnimationOptions{}); err == nil {
+		t.Errorf("WriteGIF() without a solution succeeded")
+	}
+
+	var buf bytes.Buffer
+	opts := lemin.AnimationOptions{RenderOptions: lemin.RenderOptions{Solution: solution}, Delay: 50 * time.Millisecond, FramesPerTurn: 2}
+	if err := lemin.WriteGIF(&buf, colony, opts); err != nil {
+		t.Fatalf("WriteGIF() error = %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+	if want := 1 + 2*solution.Turns; len(anim.Image) != want {
+		t.Fatalf("%d frames, want %d for %d turns", len(anim.Image), want, solution.Turns)
+	}
+	if anim.Delay[0] != 5 || anim.Delay[len(anim.Delay)-1] != 100 {
+		t.Errorf("delays = %v, want 5 and 100 for the last frame", anim.Delay)
+	}
+
+	// Room a is at 100, 40: half way through turn 1 the first ant is 30 pixels
+	// before it, at the end of turn 1 it is in it and at the end it is empty
+	canvas := image.NewRGBA(image.Rect(0, 0, anim.Config.Width, anim.Config.Height))
+	ant := color.RGBA{0xe6, 0x19, 0x4b, 0xff}
+	checks := map[int]struct {
+		x, y int
+		want color.RGBA
+	}{
+		1:                   {70, 40, ant},
+		2:                   {100, 40, ant},
+		len(anim.Image) - 1: {100, 40, color.RGBA{0x12, 0x34, 0x56, 0xff}},
+	}
+	for i, frame := range anim.Image {
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		if check, ok := checks[i]; ok {
+			if got := canvas.RGBAAt(check.x, check.y); got != check.want {
+				t.Errorf("frame %d at %d, %d = %v, want %v", i, check.x, check.y, got, check.want)
+			}
+		}
+	}
+}
+
+func TestItineraries(t *testing.T) {
+	_, solution := solvedColony(t, "3\n##start\ns 0 0\na 1 0\nb 1 1\nc 2 1\n##end\ne 3 0\ns-a\na-e\ns-b\nb-c\nc-e\n")
+
+	// Two ants take s-a-e one turn apart and one takes s-b-c-e
+	want := []lemin.Itinerary{
+		{Ant: 1, Path: 0, Depart: 1, Stops: []lemin.Stop{{"a", 1}, {"e", 2}}, Finish: 2},
+		{Ant: 2, Path: 0, Depart: 2, Stops: []lemin.Stop{{"a", 2}, {"e", 3}}, Finish: 3},
+		{Ant: 3, Path: 1, Depart: 1, Stops: []lemin.Stop{{"b", 1}, {"c", 2}, {"e", 3}}, Finish: 3},
+	}
+	got := lemin.Itineraries(solution)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Itineraries() = %+v, want %+v", got, want)
+	}
+	if got[1].Turns() != 2 || got[2].Turns() != 3 {
+		t.Errorf("Turns() = %d and %d, want 2 and 3", got[1].Turns(), got[2].Turns())
+	}
+
+	// Every stop matches a move of Simulate
+	entered := make(map[lemin.Move]int)
+	for turn, moves := range lemin.Simulate(solution) {
+		for _, move := range moves {
+			entered[move] = turn + 1
+		}
+	}
+	for _, it := range got {
+		for _, stop := range it.Stops {
+			if turn := entered[lemin.Move{Ant: it.Ant, Room: stop.Room}]; turn != stop.Turn {
+				t.Errorf("ant %d enters %s at turn %d, Simulate says %d", it.Ant, stop.Room, stop.Turn, turn)
+			}
+		}
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	colony, err := lemin.ParseFile("testdata/big_corridors.txt", lemin.ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, optimum := range []bool{false, true} {
+		if _, err := lemin.Solve(colony, lemin.SolveOptions{Context: ctx, Optimum: optimum}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Solve(Optimum: %v) with a cancelled context error = %v, want context.Canceled", optimum, err)
+		}
+	}
+}
+
+func TestOutline(t *testing.T) {
+	outline := lemin.NewOutline("2\n##start\ns 0 0\nhall 1 2\n##end\ne 3 0\ns-hall\nhall-e\n")
+	if rooms := outline.Rooms(); len(rooms) != 3 || rooms[1] != (lemin.OutlineRoom{Name: "hall", X: 1, Y: 2, Span: lemin.Span{Line: 3, Start: 0, End: 4}}) {
+		t.Errorf("Rooms() = %+v", rooms)
+	}
+	if name, found := outline.NameAt(6, 3); !found || name != "hall" || outline.Degree(name) != 2 {
+		t.Errorf("NameAt(6, 3) = %q, %v with %d links, want hall with 2", name, found, outline.Degree(name))
+	}
+
+	edits, err := outline.Rename("hall", "big hall")
+	if err == nil {
+		t.Errorf("Rename() to a name with a space = %+v, want an error", edits)
+	}
+	edits, err = outline.Rename("hall", "big-hall")
+	want := []lemin.TextEdit{
+		{Span: lemin.Span{Line: 3, Start: 0, End: 4}, Text: "big-hall"},
+		{Span: lemin.Span{Line: 6, Start: 2, End: 6}, Text: `"big-hall"`},
+		{Span: lemin.Span{Line: 7, Start: 0, End: 4}, Text: `"big-hall"`},
+	}
+	if err != nil || !reflect.DeepEqual(edits, want) {
+		t.Errorf("Rename() = %+v, %v, want %+v", edits, err, want)
+	}
+}
@@ -0,0 +1,78 @@
+package lemin
+
+import "lem-in/internal/utils"
+
+// Span is the position of a room name in a map's text: a 0-based line and the
+// byte offsets of the name within it. Quoted names include their quotes.
+type Span struct {
+	Line, Start, End int
+}
+
+// OutlineRoom is a room line of a map.
+type OutlineRoom struct {
+	Name string
+	X, Y int
+	Span Span // The name at the start of the room line
+}
+
+// TextEdit replaces the text of a span.
+type TextEdit struct {
+	Span Span
+	Text string
+}
+
+// Outline locates the room names of a map's text, for editors. Unlike Parse it
+// never fails: lines that do not parse are left out.
+type Outline struct {
+	outline *utils.Outline
+}
+
+// NewOutline locates the rooms, links and annotations in the text of a map.
+func NewOutline(text string) *Outline {
+	return &Outline{outline: utils.NewOutline(text)}
+}
+
+// Rooms returns the room lines of the map, in order.
+func (o *Outline) Rooms() []OutlineRoom {
+	rooms := make([]OutlineRoom, len(o.outline.Rooms))
+	for i, room := range o.outline.Rooms {
+		rooms[i] = outlineRoom(room)
+	}
+	return rooms
+}
+
+// Room returns the room line of the named room.
+func (o *Outline) Room(name string) (OutlineRoom, bool) {
+	room, exists := o.outline.Room(name)
+	return outlineRoom(room), exists
+}
+
+// NameAt returns the room name at a byte offset of a line, whether on its room
+// line, in a link or in an annotation.
+func (o *Outline) NameAt(line, col int) (string, bool) {
+	return o.outline.NameAt(line, col)
+}
+
+// Degree returns the number of links of the named room.
+func (o *Outline) Degree(name string) int {
+	return o.outline.Degree(name)
+}
+
+// Rename returns the edits renaming a room on its room line, in links and in
+// annotations. Names are quoted where the new name needs it.
+func (o *Outline) Rename(oldName, newName string) ([]TextEdit, error) {
+	internal, err := o.outline.Rename(oldName, newName)
+	if err != nil {
+		return nil, err
+	}
+	edits := make([]TextEdit, len(internal))
+	for i, edit := range internal {
+		edits[i] = TextEdit{Span: Span(edit.Span), Text: edit.Text}
+	}
+	return edits, nil
+}
+
+// outlineRoom converts an internal outline room.
+func outlineRoom(room utils.OutlineRoom) OutlineRoom {
+	return OutlineRoom{Name: room.Name, X: room.X, Y: room.Y, Span: Span(room.Span)}
+}
@@ -0,0 +1,204 @@
+package lemin
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RenderOptions configures RenderImage and WritePNG.
+type RenderOptions struct {
+	// Solution, when set, has its paths drawn in colour.
+	Solution *Solution
+	// Size bounds the longest side of the drawing in pixels, 1000 when zero.
+	// As in ExportSVG, one unit of the map is never drawn wider than 60 pixels.
+	Size int
+	// NoLabels leaves out the room names.
+	NoLabels bool
+	// HideUnused leaves out the rooms, other than start and end, that no path
+	// of the solution visits.
+	HideUnused bool
+}
+
+// Colours of the rendered images, matching those of ExportSVG.
+var (
+	tunnelColor = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	startColor  = color.RGBA{0x8f, 0xd1, 0x8f, 0xff}
+	endColor    = color.RGBA{0xf0, 0x80, 0x80, 0xff}
+)
+
+// namedColors are the colour names a room's color metadata may use in
+// rendered images, besides #rgb and #rrggbb.
+var namedColors = map[string]color.RGBA{
+	"black":   {0x00, 0x00, 0x00, 0xff},
+	"white":   {0xff, 0xff, 0xff, 0xff},
+	"gray":    {0x80, 0x80, 0x80, 0xff},
+	"grey":    {0x80, 0x80, 0x80, 0xff},
+	"red":     {0xff, 0x00, 0x00, 0xff},
+	"orange":  {0xff, 0xa5, 0x00, 0xff},
+	"yellow":  {0xff, 0xff, 0x00, 0xff},
+	"green":   {0x00, 0x80, 0x00, 0xff},
+	"blue":    {0x00, 0x00, 0xff, 0xff},
+	"purple":  {0x80, 0x00, 0x80, 0xff},
+	"pink":    {0xff, 0xc0, 0xcb, 0xff},
+	"brown":   {0xa5, 0x2a, 0x2a, 0xff},
+	"cyan":    {0x00, 0xff, 0xff, 0xff},
+	"magenta": {0xff, 0x00, 0xff, 0xff},
+}
+
+// WritePNG draws the colony as a PNG image, see RenderImage.
+func WritePNG(w io.Writer, c *Colony, opts RenderOptions) error {
+	return png.Encode(w, RenderImage(c, opts))
+}
+
+// RenderImage draws the colony with rooms at their coordinates, the start and
+// end rooms circled twice and the paths of the solution in the colours of
+// ExportSVG. A room's label and color metadata replace its name and fill
+// colour; colours other than #rgb, #rrggbb and common names are ignored.
+// Labels use a small built-in font with capital letters only.
+func RenderImage(c *Colony, opts RenderOptions) *image.RGBA {
+	img, _ := render(c, opts)
+	return img
+}
+
+// render draws the colony like RenderImage and also returns where its rooms are.
+func render(c *Colony, opts RenderOptions) (*image.RGBA, *layout) {
+	if opts.HideUnused {
+		c = usedPart(c, opts.Solution)
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = 1000
+	}
+	layout := newLayout(c, size, 40)
+	pathEdges := solutionEdges(opts.Solution)
+
+	img := image.NewRGBA(image.Rect(0, 0, layout.width, layout.height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	// The solution's tunnels are drawn over the others
+	for _, link := range c.Links {
+		if _, used := pathEdges[linkKey(link.From, link.To)]; !used {
+			x1, y1 := layout.point(link.From)
+			x2, y2 := layout.point(link.To)
+			drawLine(img, x1, y1, x2, y2, 1, tunnelColor)
+		}
+	}
+	for _, link := range c.Links {
+		if hex, used := pathEdges[linkKey(link.From, link.To)]; used {
+			x1, y1 := layout.point(link.From)
+			x2, y2 := layout.point(link.To)
+			stroke, _ := parseColor(hex)
+			drawLine(img, x1, y1, x2, y2, 2, stroke)
+		}
+	}
+
+	for _, room := range c.Rooms {
+		x, y := layout.point(room.Name)
+		fill := color.RGBA{0xff, 0xff, 0xff, 0xff}
+		switch room.Name {
+		case c.Start:
+			fill = startColor
+		case c.End:
+			fill = endColor
+		}
+		if value, ok := room.Meta["color"]; ok {
+			if meta, ok := parseColor(value); ok {
+				fill = meta
+			}
+		}
+		if room.Name == c.Start || room.Name == c.End {
+			fillCircle(img, x, y, 12, color.Black)
+			fillCircle(img, x, y, 11, color.White)
+		}
+		fillCircle(img, x, y, 8, color.Black)
+		fillCircle(img, x, y, 7, fill)
+
+		if !opts.NoLabels {
+			label := room.Name
+			if text, ok := room.Meta["label"]; ok {
+				label = text
+			}
+			drawText(img, x+10, y-10-glyphHeight, label, color.Black, 1)
+		}
+	}
+	return img, layout
+}
+
+// usedPart returns the colony without the rooms, other than start and end,
+// that no path of the solution visits, and without their tunnels.
+func usedPart(c *Colony, s *Solution) *Colony {
+	used := map[string]bool{c.Start: true, c.End: true}
+	if s != nil {
+		for _, path := range s.Paths {
+			for _, room := range path {
+				used[room] = true
+			}
+		}
+	}
+	part := *c
+	part.Rooms, part.Links = nil, nil
+	for _, room := range c.Rooms {
+		if used[room.Name] {
+			part.Rooms = append(part.Rooms, room)
+		}
+	}
+	for _, link := range c.Links {
+		if used[link.From] && used[link.To] {
+			part.Links = append(part.Links, link)
+		}
+	}
+	return &part
+}
+
+// drawLine draws a line from x1, y1 to x2, y2 as a run of discs of the given radius.
+func drawLine(img draw.Image, x1, y1, x2, y2, radius int, c color.Color) {
+	steps := max(abs(x2-x1), abs(y2-y1), 1)
+	for i := 0; i <= steps; i++ {
+		x := x1 + (x2-x1)*i/steps
+		y := y1 + (y2-y1)*i/steps
+		fillCircle(img, x, y, radius, c)
+	}
+}
+
+// fillCircle fills the disc of the given radius centred on x, y.
+func fillCircle(img draw.Image, x, y, radius int, c color.Color) {
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if dx*dx+dy*dy <= radius*radius {
+				img.Set(x+dx, y+dy, c)
+			}
+		}
+	}
+}
+
+// parseColor reads a colour written as #rgb, #rrggbb or one of namedColors.
+func parseColor(s string) (color.RGBA, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 3 && len(hex) != 6) {
+		return color.RGBA{}, false
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff}, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
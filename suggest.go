@@ -0,0 +1,249 @@
+package lemin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"lem-in/internal/utils"
+)
+
+// exhaustiveBudget is the most tunnel sets Suggest solves to try every
+// combination. Beyond it the best set is built greedily.
+const exhaustiveBudget = 5000
+
+// ErrInvalidCandidate is returned by Suggest when a candidate tunnel names a
+// room the colony lacks, joins a room to itself or is already a tunnel.
+var ErrInvalidCandidate = errors.New("invalid candidate tunnel")
+
+// SuggestOptions configures Suggest.
+type SuggestOptions struct {
+	// Distance makes every pair of unlinked rooms at most this far apart a
+	// candidate tunnel. It is ignored when Candidates is set.
+	Distance float64
+	// Candidates lists the tunnels to consider.
+	Candidates []Link
+	// Max is the most tunnels added together, 1 when zero.
+	Max int
+}
+
+// Suggestion is a set of new tunnels and the turns the colony needs with them.
+type Suggestion struct {
+	Links []Link `json:"links"`
+	Turns int    `json:"turns"`
+	Saved int    `json:"saved"` // Turns saved, negative when the tunnels make the solution worse
+}
+
+// Suggestions is the result of Suggest.
+type Suggestions struct {
+	Turns      int          `json:"turns"`      // Turns the colony needs as it is
+	Tunnels    []Suggestion `json:"tunnels"`    // Each candidate added on its own, most turns saved first
+	Best       Suggestion   `json:"best"`       // The set of up to Max tunnels saving the most turns
+	Exhaustive bool         `json:"exhaustive"` // Whether every set was tried, rather than building Best greedily
+}
+
+// Suggest evaluates new tunnels for the colony. Each candidate is solved on
+// its own, then the set of up to opts.Max candidates saving the most turns is
+// searched, trying every combination when there are few enough and adding the
+// best tunnel one at a time otherwise. Turns are those Solve finds for the
+// colony's ants.
+func Suggest(c *Colony, opts SuggestOptions) (*Suggestions, error) {
+	maxLinks := max(opts.Max, 1)
+	candidates, err := candidateLinks(c, opts)
+	if err != nil {
+		return nil, err
+	}
+	base := turnsWith(c, nil)
+	if base < 0 {
+		return nil, ErrNoPath
+	}
+
+	result := &Suggestions{Turns: base, Best: Suggestion{Links: []Link{}, Turns: base}}
+	singles := make([][]Link, len(candidates))
+	for i, link := range candidates {
+		singles[i] = []Link{link}
+	}
+	for i, turns := range evaluateLinks(c, singles) {
+		result.Tunnels = append(result.Tunnels, Suggestion{Links: singles[i], Turns: turns, Saved: base - turns})
+	}
+	sort.SliceStable(result.Tunnels, func(i, j int) bool { return result.Tunnels[i].Turns < result.Tunnels[j].Turns })
+
+	consider := func(links []Link, turns int) {
+		if turns < result.Best.Turns || turns == result.Best.Turns && len(links) < len(result.Best.Links) {
+			result.Best = Suggestion{Links: links, Turns: turns, Saved: base - turns}
+		}
+	}
+	for _, single := range result.Tunnels {
+		consider(single.Links, single.Turns)
+	}
+	maxLinks = min(maxLinks, len(candidates))
+	if maxLinks < 2 {
+		result.Exhaustive = true
+		return result, nil
+	}
+
+	if combinations(len(candidates), maxLinks) <= exhaustiveBudget {
+		result.Exhaustive = true
+		var sets [][]Link
+		for size := 2; size <= maxLinks; size++ {
+			sets = append(sets, linkSets(candidates, size)...)
+		}
+		for i, turns := range evaluateLinks(c, sets) {
+			consider(sets[i], turns)
+		}
+		return result, nil
+	}
+
+	// Greedily add the tunnel saving the most to the best single one. That one
+	// may save nothing yet, as a new route can need several tunnels, so the set
+	// grows as long as it does not get worse.
+	chosen, chosenTurns := result.Tunnels[0].Links, result.Tunnels[0].Turns
+	for len(chosen) < maxLinks {
+		var sets [][]Link
+		for _, link := range candidates {
+			if !containsLink(chosen, link) {
+				sets = append(sets, append(append([]Link{}, chosen...), link))
+			}
+		}
+		best, bestTurns := -1, 0
+		for i, turns := range evaluateLinks(c, sets) {
+			consider(sets[i], turns)
+			if best < 0 || turns < bestTurns {
+				best, bestTurns = i, turns
+			}
+		}
+		if bestTurns > chosenTurns {
+			break
+		}
+		chosen, chosenTurns = sets[best], bestTurns
+	}
+	return result, nil
+}
+
+// candidateLinks returns the candidate tunnels: the given ones, checked, or
+// the pairs of unlinked rooms within the distance, in declaration order.
+func candidateLinks(c *Colony, opts SuggestOptions) ([]Link, error) {
+	linked := make(map[string]bool, len(c.Links))
+	for _, link := range c.Links {
+		linked[linkKey(link.From, link.To)] = true
+	}
+
+	if len(opts.Candidates) > 0 {
+		rooms := make(map[string]bool, len(c.Rooms))
+		for _, room := range c.Rooms {
+			rooms[room.Name] = true
+		}
+		seen := make(map[string]bool, len(opts.Candidates))
+		for _, link := range opts.Candidates {
+			for _, name := range []string{link.From, link.To} {
+				if !rooms[name] {
+					return nil, fmt.Errorf("%w: room does not exist: %s", ErrInvalidCandidate, name)
+				}
+			}
+			key := linkKey(link.From, link.To)
+			if link.From == link.To || linked[key] || seen[key] {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidCandidate, utils.FormatLink(link.From, link.To))
+			}
+			seen[key] = true
+		}
+		return opts.Candidates, nil
+	}
+
+	if opts.Distance <= 0 {
+		return nil, errors.New("no candidate tunnels: set a distance or list the candidates")
+	}
+	var candidates []Link
+	for i, a := range c.Rooms {
+		for _, b := range c.Rooms[i+1:] {
+			if !linked[linkKey(a.Name, b.Name)] && math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y)) <= opts.Distance {
+				candidates = append(candidates, Link{From: a.Name, To: b.Name})
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// turnsWith returns the turns Solve needs once the links are added, or -1
+// when the end room still cannot be reached.
+func turnsWith(c *Colony, links []Link) int {
+	colony := c.antColony()
+	for _, link := range links {
+		colony.Links[link.From] = append(colony.Links[link.From], link.To)
+		colony.Links[link.To] = append(colony.Links[link.To], link.From)
+	}
+	paths, _, turns := utils.FindPaths(colony)
+	if len(paths) == 0 {
+		return -1
+	}
+	return turns
+}
+
+// evaluateLinks solves the colony with each set of links added, using every CPU.
+func evaluateLinks(c *Colony, sets [][]Link) []int {
+	turns := make([]int, len(sets))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), len(sets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				turns[i] = turnsWith(c, sets[i])
+			}
+		}()
+	}
+	for i := range sets {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return turns
+}
+
+// linkSets returns every set of size links, in candidate order.
+func linkSets(candidates []Link, size int) [][]Link {
+	var sets [][]Link
+	var build func(start int, set []Link)
+	build = func(start int, set []Link) {
+		if len(set) == size {
+			sets = append(sets, append([]Link{}, set...))
+			return
+		}
+		for i := start; i <= len(candidates)-(size-len(set)); i++ {
+			build(i+1, append(set, candidates[i]))
+		}
+	}
+	build(0, nil)
+	return sets
+}
+
+// combinations counts the sets of 2 to k of n candidates, stopping once it
+// exceeds the exhaustive budget.
+func combinations(n, k int) int {
+	total := 0
+	for size := 2; size <= k; size++ {
+		count := 1
+		for i := 1; i <= size; i++ {
+			count = count * (n - size + i) / i
+			if count > exhaustiveBudget {
+				return count
+			}
+		}
+		if total += count; total > exhaustiveBudget {
+			return total
+		}
+	}
+	return total
+}
+
+func containsLink(links []Link, link Link) bool {
+	for _, l := range links {
+		if linkKey(l.From, l.To) == linkKey(link.From, link.To) {
+			return true
+		}
+	}
+	return false
+}